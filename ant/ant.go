@@ -27,6 +27,7 @@ type Ant interface {
 	ReachedSugar(Sugar)
 	SeeFriend(Ant)
 	SeeMark(Mark)
+	Hear(from FriendInfo, msg int)
 	Tick()
 }
 
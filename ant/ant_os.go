@@ -33,4 +33,10 @@ type AntOs interface {
 	TakeSugar(sugar Sugar) error
 	GotToAntHill()
 	SetMark(radius int, information int)
+	Colony() Colony
+	GetOrder() int
+	Say(msg int) error
+	// Log logs a message with optional key-value pairs, like slog. The entry is tagged with the tick, the id, the role
+	// and the colony of the ant.
+	Log(msg string, args ...any)
 }
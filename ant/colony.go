@@ -0,0 +1,40 @@
+/*
+Copyright (c) 2024 Sebastian Kroczek <me@xbug.de>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package ant
+
+import "errors"
+
+// ErrColonyMemoryFull is returned by Colony.Set if the memory limit of the colony is reached.
+var ErrColonyMemoryFull = errors.New("colony memory is full")
+
+// Colony is the memory shared by all ants of a colony.
+// Values written with Set or removed with Delete become visible with the next tick, so all ants of a colony see
+// the same values during a tick. If several ants write the same key in one tick, the ant with the highest id wins.
+// Stored values should be treated as immutable, changing them in place bypasses these rules.
+type Colony interface {
+	Get(key string) (interface{}, bool)
+	Set(key string, value interface{}) error
+	Delete(key string)
+	Keys() []string
+	Len() int
+}
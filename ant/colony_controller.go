@@ -0,0 +1,57 @@
+/*
+Copyright (c) 2024 Sebastian Kroczek <me@xbug.de>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package ant
+
+// ColonyStats is a summary of a colony, it is handed to the ColonyController every tick.
+type ColonyStats struct {
+	Tick           int
+	Ants           int
+	RolesCount     RolesCount
+	IdleAnts       int // ants that are neither moving nor turning
+	Sugar          int // sugar stored in the ant hill
+	SugarInTransit int // sugar carried by the ants
+	Marks          int
+}
+
+// ColonyOs is the interface of the simulation to a ColonyController.
+type ColonyOs interface {
+	// Colony returns the shared memory of the colony. Unlike writes of ants, writes of the controller are visible
+	// immediately, because the controller is ticked before the ants.
+	Colony() Colony
+	// SetOrder broadcasts an order to all ants of the colony, ants read it with AntOs.GetOrder.
+	SetOrder(order int)
+	// SetSpawnRole sets the role of newly spawned ants. An empty role hands the choice back to ChooseRole.
+	SetSpawnRole(role string)
+}
+
+// ColonyController is ticked once per simulation tick, before the ants of the colony are updated.
+// Additionally, a controller can react to events of the colony by implementing any of the following methods:
+//
+//	AntSpawned(antId int, role string)
+//	AntDied(antId int)
+//	SugarDelivered(antId int, amount int)
+//
+// Events of a tick are delivered ordered by ant id right before the next call of Tick.
+type ColonyController interface {
+	Tick(ColonyOs, ColonyStats)
+}
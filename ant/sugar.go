@@ -22,5 +22,6 @@ THE SOFTWARE.
 package ant
 
 type Sugar interface {
+	GetId() int
 	GetCurrentSugar() int
 }
@@ -48,6 +48,10 @@ func (d *UnimplementedAnt) SeeMark(mark Mark) {
 	// Do nothing, I'm a dummy
 }
 
+func (d *UnimplementedAnt) Hear(from FriendInfo, msg int) {
+	// Do nothing, I'm a dummy
+}
+
 func (d *UnimplementedAnt) Tick() {
 	// Do nothing, I'm a dummy
 }
@@ -0,0 +1,238 @@
+/*
+Copyright (c) 2024 Sebastian Kroczek <me@xbug.de>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package core
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"runtime"
+	"sort"
+	"strconv"
+	"sync"
+)
+
+// BatchSpec describes a batch of headless runs that only differ in their seeds.
+type BatchSpec struct {
+	// Runs is the number of simulations, it is ignored if Seeds are given
+	Runs int
+	// Seeds of the simulations, by default the runs are seeded with BaseSeed, BaseSeed+1, ...
+	Seeds    []uint64
+	BaseSeed uint64
+	// Ticks every simulation runs
+	Ticks int
+	// Workers is the number of simulations that run concurrently, by default one per CPU
+	Workers int
+	// Options are applied to every run, they must be safe to share between concurrent runs
+	Options []Option
+	// RunOptions returns additional options for a single run, e.g. for ants that hold resources like a process
+	RunOptions func(run int) ([]Option, error)
+}
+
+// RunStats are the statistics of a single run of a batch.
+type RunStats struct {
+	Seed   uint64 `json:"seed"`
+	Ticks  int    `json:"ticks"`
+	Faults int    `json:"faults"`
+	Error  string `json:"error,omitempty"`
+	Statistics
+}
+
+// Summary sums up a statistic over the runs of a batch.
+type Summary struct {
+	Count  int     `json:"count"`
+	Mean   float64 `json:"mean"`
+	Median float64 `json:"median"`
+	P10    float64 `json:"p10"`
+	P25    float64 `json:"p25"`
+	P75    float64 `json:"p75"`
+	P90    float64 `json:"p90"`
+	Min    float64 `json:"min"`
+	Max    float64 `json:"max"`
+}
+
+// BatchResult holds the runs of a batch and the summaries of their statistics.
+// FirstDelivery only counts the runs in which sugar was delivered at all.
+type BatchResult struct {
+	Runs           []RunStats `json:"runs"`
+	SugarDelivered Summary    `json:"sugarDelivered"`
+	AntsLost       Summary    `json:"antsLost"`
+	FirstDelivery  Summary    `json:"firstDelivery"`
+}
+
+// RunBatch runs the simulations of the batch headless and concurrently.
+// A failing run does not stop the batch, its error is recorded in its RunStats.
+func RunBatch(spec BatchSpec) (*BatchResult, error) {
+	if spec.Ticks <= 0 {
+		return nil, errors.New("batch needs a positive number of ticks")
+	}
+	seeds := spec.Seeds
+	if len(seeds) == 0 {
+		for i := 0; i < spec.Runs; i++ {
+			seeds = append(seeds, spec.BaseSeed+uint64(i))
+		}
+	}
+	if len(seeds) == 0 {
+		return nil, errors.New("batch needs at least one run")
+	}
+	workers := spec.Workers
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+
+	result := &BatchResult{Runs: make([]RunStats, len(seeds))}
+	runs := make(chan int)
+	wg := sync.WaitGroup{}
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for run := range runs {
+				result.Runs[run] = runOne(spec, run, seeds[run])
+			}
+		}()
+	}
+	for run := range seeds {
+		runs <- run
+	}
+	close(runs)
+	wg.Wait()
+
+	var sugar, lost, first []float64
+	for _, r := range result.Runs {
+		sugar = append(sugar, float64(r.SugarDelivered))
+		lost = append(lost, float64(r.AntsLost))
+		if r.FirstDelivery > 0 {
+			first = append(first, float64(r.FirstDelivery))
+		}
+	}
+	result.SugarDelivered = summarize(sugar)
+	result.AntsLost = summarize(lost)
+	result.FirstDelivery = summarize(first)
+	return result, nil
+}
+
+func runOne(spec BatchSpec, run int, seed uint64) RunStats {
+	options := append([]Option(nil), spec.Options...)
+	if spec.RunOptions != nil {
+		runOptions, err := spec.RunOptions(run)
+		if err != nil {
+			return RunStats{Seed: seed, Error: err.Error()}
+		}
+		options = append(options, runOptions...)
+	}
+	return runHeadless(options, spec.Ticks, seed)
+}
+
+// runHeadless runs a single simulation for the given ticks as fast as possible.
+func runHeadless(options []Option, ticks int, seed uint64) RunStats {
+	stats := RunStats{Seed: seed}
+	options = append(options, Headless(), Unthrottled(), WithTicks(ticks), WithSeed(seed))
+	result, err := Run(options...)
+	if err != nil {
+		stats.Error = err.Error()
+	}
+	if result != nil {
+		stats.Ticks = result.Ticks
+		stats.Faults = len(result.Faults)
+		stats.Statistics = result.Statistics
+	}
+	return stats
+}
+
+func summarize(values []float64) Summary {
+	if len(values) == 0 {
+		return Summary{}
+	}
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	sum := 0.0
+	for _, v := range sorted {
+		sum += v
+	}
+	return Summary{
+		Count:  len(sorted),
+		Mean:   sum / float64(len(sorted)),
+		Median: percentile(sorted, 50),
+		P10:    percentile(sorted, 10),
+		P25:    percentile(sorted, 25),
+		P75:    percentile(sorted, 75),
+		P90:    percentile(sorted, 90),
+		Min:    sorted[0],
+		Max:    sorted[len(sorted)-1],
+	}
+}
+
+// percentile interpolates linearly between the closest ranks of the sorted values.
+func percentile(sorted []float64, p float64) float64 {
+	rank := p / 100 * float64(len(sorted)-1)
+	lower := int(math.Floor(rank))
+	upper := int(math.Ceil(rank))
+	return sorted[lower] + (sorted[upper]-sorted[lower])*(rank-float64(lower))
+}
+
+func (r *BatchResult) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r)
+}
+
+// WriteCSV writes the summaries, one statistic per row.
+func (r *BatchResult) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	_ = cw.Write([]string{"statistic", "count", "mean", "median", "p10", "p25", "p75", "p90", "min", "max"})
+	for _, row := range []struct {
+		name string
+		Summary
+	}{
+		{"sugar_delivered", r.SugarDelivered},
+		{"ants_lost", r.AntsLost},
+		{"first_delivery", r.FirstDelivery},
+	} {
+		s := row.Summary
+		_ = cw.Write([]string{row.name, strconv.Itoa(s.Count), formatFloat(s.Mean), formatFloat(s.Median),
+			formatFloat(s.P10), formatFloat(s.P25), formatFloat(s.P75), formatFloat(s.P90),
+			formatFloat(s.Min), formatFloat(s.Max)})
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// WriteRunsCSV writes the statistics of the single runs, one run per row.
+func (r *BatchResult) WriteRunsCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	_ = cw.Write([]string{"seed", "ticks", "sugar_delivered", "ants_lost", "first_delivery", "faults", "error"})
+	for _, run := range r.Runs {
+		_ = cw.Write([]string{fmt.Sprint(run.Seed), strconv.Itoa(run.Ticks), strconv.Itoa(run.SugarDelivered),
+			strconv.Itoa(run.AntsLost), strconv.Itoa(run.FirstDelivery), strconv.Itoa(run.Faults), run.Error})
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
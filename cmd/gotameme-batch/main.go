@@ -0,0 +1,114 @@
+/*
+Copyright (c) 2024 Sebastian Kroczek <me@xbug.de>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+// Gotameme-batch runs many headless simulations with different seeds and prints statistics about them.
+// The ants are driven by an external bot, a WebAssembly module or a Starlark script.
+//
+//	go run ./cmd/gotameme-batch -runs 100 -ticks 5000 -bot "go run ./external/echobot"
+package main
+
+import (
+	"errors"
+	"flag"
+	"github.com/gotameme/core"
+	"github.com/gotameme/core/script"
+	"io"
+	"log"
+	"os"
+	"strings"
+)
+
+func main() {
+	var (
+		runs     = flag.Int("runs", 10, "number of simulations")
+		seed     = flag.Uint64("seed", 1, "seed of the first simulation, the others count up")
+		ticks    = flag.Int("ticks", 5000, "ticks per simulation")
+		workers  = flag.Int("workers", 0, "simulations that run concurrently, 0 means one per CPU")
+		ants     = flag.Int("ants", 100, "desired number of ants")
+		sugar    = flag.Int("sugar", 1, "desired number of sugar heaps")
+		bot      = flag.String("bot", "", "command of an external bot that drives the ants")
+		wasmPath = flag.String("wasm", "", "WebAssembly module that drives the ants")
+		starPath = flag.String("script", "", "Starlark script that drives the ants")
+		format   = flag.String("format", "json", "output format, json or csv")
+		perRun   = flag.Bool("per-run", false, "write one csv row per run instead of the summary")
+		out      = flag.String("out", "", "output file, stdout if empty")
+	)
+	flag.Parse()
+
+	// check the flags first, a typo must not cost a whole batch
+	var write func(result *core.BatchResult, w io.Writer) error
+	switch {
+	case *format == "json":
+		write = (*core.BatchResult).WriteJSON
+	case *format == "csv" && *perRun:
+		write = (*core.BatchResult).WriteRunsCSV
+	case *format == "csv":
+		write = (*core.BatchResult).WriteCSV
+	default:
+		log.Fatal(errors.New("unknown format " + *format))
+	}
+
+	result, err := core.RunBatch(core.BatchSpec{
+		Runs:     *runs,
+		BaseSeed: *seed,
+		Ticks:    *ticks,
+		Workers:  *workers,
+		Options:  []core.Option{core.WithDesiredAnts(*ants), core.WithDesiredSugar(*sugar), core.WithDeterministicUpdates()},
+		// every run gets its own bot, module or script, because they hold state
+		RunOptions: func(int) ([]core.Option, error) {
+			switch {
+			case *bot != "":
+				command := strings.Fields(*bot)
+				return []core.Option{core.WithExternalAnts(command[0], command[1:]...)}, nil
+			case *wasmPath != "":
+				option, err := core.WithWasmAnts(*wasmPath)
+				return []core.Option{option}, err
+			case *starPath != "":
+				option, err := core.WithScriptedAnts(*starPath, script.WithReloadInterval(0))
+				return []core.Option{option}, err
+			}
+			return nil, nil
+		},
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if *out == "" {
+		if err = write(result, os.Stdout); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+	file, err := os.Create(*out)
+	if err != nil {
+		log.Fatal(err)
+	}
+	err = write(result, file)
+	// log.Fatal skips deferred calls, so the file is closed before
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+}
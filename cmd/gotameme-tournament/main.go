@@ -0,0 +1,100 @@
+/*
+Copyright (c) 2024 Sebastian Kroczek <me@xbug.de>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+// Gotameme-tournament ranks external bots against each other and prints the standings.
+//
+//	go run ./cmd/gotameme-tournament -bot "echo=go run ./external/echobot" -bot "other=./other-bot" -seeds 5
+package main
+
+import (
+	"flag"
+	"github.com/gotameme/core"
+	"log"
+	"os"
+	"strings"
+)
+
+// bots collects the repeated -bot flags.
+type bots []core.Entrant
+
+func (b *bots) String() string {
+	return ""
+}
+
+func (b *bots) Set(value string) error {
+	name, command, ok := strings.Cut(value, "=")
+	fields := strings.Fields(command)
+	if !ok || name == "" || len(fields) == 0 {
+		return flag.ErrHelp
+	}
+	*b = append(*b, core.BotEntrant(name, fields[0], fields[1:]...))
+	return nil
+}
+
+func main() {
+	var entrants bots
+	flag.Var(&entrants, "bot", "name=command of a bot, can be repeated")
+	var (
+		seeds   = flag.Int("seeds", 3, "number of seeds every pairing plays")
+		seed    = flag.Uint64("seed", 1, "first seed, the others count up")
+		ticks   = flag.Int("ticks", 5000, "ticks per game")
+		swiss   = flag.Bool("swiss", false, "use Swiss pairing instead of round-robin")
+		rounds  = flag.Int("rounds", 0, "rounds of a Swiss tournament, 0 means enough to find a winner")
+		workers = flag.Int("workers", 0, "games that run concurrently, 0 means one per CPU")
+		matches = flag.String("matches", "", "csv file for the result of every game")
+	)
+	flag.Parse()
+
+	spec := core.TournamentSpec{
+		Entrants: entrants,
+		Ticks:    *ticks,
+		Rounds:   *rounds,
+		Workers:  *workers,
+	}
+	for i := 0; i < *seeds; i++ {
+		spec.Seeds = append(spec.Seeds, *seed+uint64(i))
+	}
+	if *swiss {
+		spec.Pairing = core.Swiss
+	}
+	result, err := core.RunTournament(spec)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err = result.WriteStandings(os.Stdout); err != nil {
+		log.Fatal(err)
+	}
+	if *matches != "" {
+		file, err := os.Create(*matches)
+		if err != nil {
+			log.Fatal(err)
+		}
+		err = result.WriteMatchesCSV(file)
+		// log.Fatal skips deferred calls, so the file is closed before
+		if closeErr := file.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+}
@@ -25,8 +25,20 @@ package core
 import (
 	"fmt"
 	"github.com/gotameme/core/ant"
+	"github.com/gotameme/core/external"
 	"github.com/gotameme/core/internal"
+	"github.com/gotameme/core/internal/control"
+	"github.com/gotameme/core/internal/metrics"
+	"github.com/gotameme/core/internal/render"
+	"github.com/gotameme/core/internal/replay"
 	"github.com/gotameme/core/internal/simulation"
+	"github.com/gotameme/core/internal/terminal"
+	"github.com/gotameme/core/script"
+	"github.com/gotameme/core/wasm"
+	"io"
+	"log/slog"
+	"os"
+	"time"
 )
 
 const (
@@ -52,6 +64,25 @@ type Configuration struct {
 	ScreenWidth, ScreenHeight, TPS int
 	headless                       bool
 	internal.GameConfiguration
+	// closers are closed when the run ends
+	closers []io.Closer
+	// restore is the path of a saved simulation the run continues from
+	restore string
+	// throttled limits a headless run to TPS ticks per second
+	throttled bool
+	// ticks ends a headless run after the given number of ticks, 0 means never
+	ticks int
+	// control serves the status of the run over HTTP and takes commands
+	control *control.Server
+	// heatmap is the path the heatmap is written to when the run ends
+	heatmap string
+	// terminal draws a headless run as text
+	terminal *terminal.Terminal
+	// gif is the path the animated GIF is written to when the run ends, every gifInterval ticks is a frame
+	gif         string
+	gifInterval int
+	// err is the first error of an option applied to the configuration, Run returns it before the run starts
+	err error
 }
 
 func NewConfiguration(opts ...Option) *Configuration {
@@ -65,6 +96,13 @@ func NewConfiguration(opts ...Option) *Configuration {
 	return globalOptions
 }
 
+// fail records the error of an option, the first one is kept.
+func (cnf *Configuration) fail(err error) {
+	if cnf.err == nil {
+		cnf.err = err
+	}
+}
+
 func WithLayout(screenWidth, screenHeight int) Option {
 	return func(cnf *Configuration) {
 		cnf.ScreenWidth = screenWidth
@@ -78,6 +116,7 @@ func WithTPS(tps int) Option {
 	}
 	return func(cnf *Configuration) {
 		cnf.TPS = tps
+		internal.WithTPS(tps)(&cnf.GameConfiguration)
 	}
 
 }
@@ -88,6 +127,57 @@ func Headless() Option {
 	}
 }
 
+// Unthrottled runs a headless simulation as fast as possible, this is the default.
+func Unthrottled() Option {
+	return func(cnf *Configuration) {
+		cnf.throttled = false
+	}
+}
+
+// Throttled runs a headless simulation in real time, with the ticks per second of WithTPS.
+func Throttled() Option {
+	return func(cnf *Configuration) {
+		cnf.throttled = true
+	}
+}
+
+// WithControlServer serves the status of the run over HTTP at addr and takes commands to pause, resume, step,
+// change the speed and stop it, see package internal/control for the endpoints. A missing host means localhost.
+// Every run gets its own server, runs at the same time need different addresses.
+func WithControlServer(addr string) Option {
+	return func(cnf *Configuration) {
+		server := control.New(addr)
+		cnf.control = server
+		internal.WithRemote(server)(&cnf.GameConfiguration)
+		simulation.WithTickRecorder(server)(&cnf.GameConfiguration.SimulationConfig)
+		cnf.closers = append(cnf.closers, server)
+	}
+}
+
+// WithTerminalRenderer runs the simulation headless and draws it as text with ANSI colours instead of opening a
+// window, so it can be watched over SSH. The run is throttled, Unthrottled after this option lifts that. Space
+// pauses, s and S step, + and - change the speed and q stops the run.
+func WithTerminalRenderer() Option {
+	return func(cnf *Configuration) {
+		renderer := terminal.New()
+		cnf.headless = true
+		cnf.throttled = true
+		cnf.terminal = renderer
+		simulation.WithTickRecorder(renderer)(&cnf.GameConfiguration.SimulationConfig)
+		cnf.closers = append(cnf.closers, renderer)
+	}
+}
+
+// WithTicks ends a headless run after the given number of ticks.
+func WithTicks(ticks int) Option {
+	if ticks <= 0 {
+		panic("Ticks must be greater than 0")
+	}
+	return func(cnf *Configuration) {
+		cnf.ticks = ticks
+	}
+}
+
 // endregion
 
 // region Game Options
@@ -127,6 +217,226 @@ func WithAntConstructor(antConstructor ant.AntConstructor) Option {
 	}
 }
 
+// WithColonyMemoryLimit limits the number of keys the shared memory of a colony can hold (see ant.Colony).
+func WithColonyMemoryLimit(limit int) Option {
+	if limit < 0 {
+		panic("Colony memory limit must not be negative")
+	}
+	return func(cnf *Configuration) {
+		simulation.WithColonyMemoryLimit(limit)(&cnf.GameConfiguration.SimulationConfig)
+	}
+}
+
+// WithColonyController registers a controller that plans for the whole colony, see ant.ColonyController.
+func WithColonyController(controller ant.ColonyController) Option {
+	return func(cnf *Configuration) {
+		simulation.WithColonyController(controller)(&cnf.GameConfiguration.SimulationConfig)
+	}
+}
+
+// WithHearingRadius defines how far a message said by an ant with AntOs.Say can be heard.
+func WithHearingRadius(radius int) Option {
+	if radius < 0 {
+		panic("Hearing radius must not be negative")
+	}
+	return func(cnf *Configuration) {
+		simulation.WithHearingRadius(radius)(&cnf.GameConfiguration.SimulationConfig)
+	}
+}
+
+// WithMessageBandwidth defines how many messages an ant can say per tick.
+func WithMessageBandwidth(messages int) Option {
+	if messages < 0 {
+		panic("Message bandwidth must not be negative")
+	}
+	return func(cnf *Configuration) {
+		simulation.WithMessageBandwidth(messages)(&cnf.GameConfiguration.SimulationConfig)
+	}
+}
+
+// WithColonyName names the colony, the name is used to report faults.
+func WithColonyName(name string) Option {
+	return func(cnf *Configuration) {
+		simulation.WithColonyName(name)(&cnf.GameConfiguration.SimulationConfig)
+	}
+}
+
+// WithFaultPolicy defines what happens to an ant whose callback panicked. The default is AbortSimulation.
+func WithFaultPolicy(policy FaultPolicy) Option {
+	return func(cnf *Configuration) {
+		simulation.WithFaultPolicy(policy)(&cnf.GameConfiguration.SimulationConfig)
+	}
+}
+
+// WithBudgets limits the time a single callback and all callbacks of a colony per tick may take, 0 means unlimited.
+// Offenders are handled according to the policy, penaltyTicks is only used by PenaliseOffender.
+func WithBudgets(callbackBudget, tickBudget time.Duration, policy BudgetPolicy, penaltyTicks int) Option {
+	if callbackBudget < 0 || tickBudget < 0 || penaltyTicks < 0 {
+		panic("Budgets and penalty ticks must not be negative")
+	}
+	return func(cnf *Configuration) {
+		simulation.WithBudgets(callbackBudget, tickBudget, policy, penaltyTicks)(&cnf.GameConfiguration.SimulationConfig)
+	}
+}
+
+// WithExternalAnts lets an external process drive the ants, see package external for the protocol.
+// The process is started with the first ant and stopped when the run ends. Every run gets its own bot, so the option
+// can be reused across runs.
+func WithExternalAnts(command string, args ...string) Option {
+	return func(cnf *Configuration) {
+		bot := external.NewBot(command, args)
+		simulation.WithAntConstructor(bot.AntConstructor())(&cnf.GameConfiguration.SimulationConfig)
+		cnf.closers = append(cnf.closers, bot)
+	}
+}
+
+// WithWasmAnts runs the ants in a sandbox from a WebAssembly module, see package wasm for the interface of the module.
+// The module is compiled once and kept for the life of the program, so the option can be reused across runs. Every
+// run gets its own session, which releases the instances of its ants when the run ends.
+func WithWasmAnts(path string, options ...wasm.Option) (Option, error) {
+	module, err := wasm.Load(path, options...)
+	if err != nil {
+		return nil, err
+	}
+	return func(cnf *Configuration) {
+		session := module.NewSession()
+		simulation.WithAntConstructor(session.AntConstructor())(&cnf.GameConfiguration.SimulationConfig)
+		cnf.closers = append(cnf.closers, session)
+	}, nil
+}
+
+// WithScriptedAnts drives the ants by a Starlark script, see package script for the callbacks and functions.
+// The script is reloaded when the file changes.
+func WithScriptedAnts(path string, options ...script.Option) (Option, error) {
+	s, err := script.Load(path, options...)
+	if err != nil {
+		return nil, err
+	}
+	return func(cnf *Configuration) {
+		simulation.WithAntConstructor(s.AntConstructor())(&cnf.GameConfiguration.SimulationConfig)
+	}, nil
+}
+
+// WithSeed seeds the random numbers of the simulation, the same seed places the hill and the sugar at the same positions.
+func WithSeed(seed uint64) Option {
+	return func(cnf *Configuration) {
+		simulation.WithSeed(seed)(&cnf.GameConfiguration.SimulationConfig)
+	}
+}
+
+// WithReplayRecorder records every tick to a replay file at path, which can be played back with Replay.
+// Every run gets its own recorder, a reused option writes the replay of the last run.
+func WithReplayRecorder(path string) Option {
+	return func(cnf *Configuration) {
+		recorder := replay.NewRecorder(path)
+		simulation.WithTickRecorder(recorder)(&cnf.GameConfiguration.SimulationConfig)
+		cnf.closers = append(cnf.closers, recorder)
+	}
+}
+
+// WithDeterministicUpdates updates the ants one after another instead of in parallel.
+// Together with WithSeed and deterministic ants, every run gives the same result.
+func WithDeterministicUpdates() Option {
+	return func(cnf *Configuration) {
+		simulation.WithDeterministicUpdates()(&cnf.GameConfiguration.SimulationConfig)
+	}
+}
+
+// WithCheckpoints saves the simulation to path every interval ticks, the run can be continued with WithRestore.
+func WithCheckpoints(path string, interval int) Option {
+	if interval <= 0 {
+		panic("Checkpoint interval must be greater than 0")
+	}
+	c := &checkpointer{path: path, interval: interval}
+	return func(cnf *Configuration) {
+		simulation.WithTickRecorder(c)(&cnf.GameConfiguration.SimulationConfig)
+	}
+}
+
+// WithRestore continues the run from a simulation saved with WithCheckpoints.
+// The other options, especially the ants, have to match the saved run.
+func WithRestore(path string) Option {
+	return func(cnf *Configuration) {
+		cnf.restore = path
+	}
+}
+
+// WithMetrics samples the simulation every interval ticks and writes the time series to path,
+// as CSV with MetricsCSV or as one JSON object per line with MetricsJSONLines.
+// Every run gets its own recorder, a reused option writes the file of the last run.
+func WithMetrics(path string, interval int, format MetricsFormat) Option {
+	if interval <= 0 {
+		panic("Metrics interval must be greater than 0")
+	}
+	return func(cnf *Configuration) {
+		recorder := metrics.NewRecorder(path, interval, format)
+		simulation.WithObserver(recorder)(&cnf.GameConfiguration.SimulationConfig)
+		simulation.WithTickRecorder(recorder)(&cnf.GameConfiguration.SimulationConfig)
+		cnf.closers = append(cnf.closers, recorder)
+	}
+}
+
+// WithSnapshots writes a PNG of the world at the given ticks, drawn without a GPU so it works in headless runs too.
+// The file name is the pattern formatted with the tick, like "shot-%04d.png".
+func WithSnapshots(pattern string, ticks ...int) Option {
+	snapshots := render.NewSnapshots(pattern, ticks...)
+	return func(cnf *Configuration) {
+		simulation.WithTickRecorder(snapshots)(&cnf.GameConfiguration.SimulationConfig)
+	}
+}
+
+// WithGIF records the world every interval ticks and writes an animated GIF to path when the run ends.
+// The animation plays at the ticks per second of WithTPS.
+func WithGIF(path string, interval int) Option {
+	if interval <= 0 {
+		panic("GIF interval must be greater than 0")
+	}
+	return func(cnf *Configuration) {
+		cnf.gif = path
+		cnf.gifInterval = interval
+	}
+}
+
+// WithLogHandler routes the log of the engine and, unless WithAntLogFile is used, the log of the ants to handler.
+// Without it the default logger of slog is used.
+func WithLogHandler(handler slog.Handler) Option {
+	return func(cnf *Configuration) {
+		simulation.WithLogHandler(handler)(&cnf.GameConfiguration.SimulationConfig)
+	}
+}
+
+// WithLogLevel drops log entries below level, the default and nil mean slog.LevelInfo.
+func WithLogLevel(level slog.Leveler) Option {
+	return func(cnf *Configuration) {
+		simulation.WithLogLevel(level)(&cnf.GameConfiguration.SimulationConfig)
+	}
+}
+
+// WithAntLogFile writes the log of the ants to path, one JSON object per line. The file is created by every run,
+// Run fails if it can't be created.
+func WithAntLogFile(path string) Option {
+	return func(cnf *Configuration) {
+		file, err := os.Create(path)
+		if err != nil {
+			cnf.fail(err)
+			return
+		}
+		handler := slog.NewJSONHandler(file, &slog.HandlerOptions{Level: slog.LevelDebug})
+		simulation.WithAntLogHandler(handler)(&cnf.GameConfiguration.SimulationConfig)
+		cnf.closers = append(cnf.closers, file)
+	}
+}
+
+// WithAntLogBuffer defines how many log entries are kept per ant to be shown in the log panel, 0 keeps none.
+func WithAntLogBuffer(entries int) Option {
+	if entries < 0 {
+		panic("Ant log buffer must not be negative")
+	}
+	return func(cnf *Configuration) {
+		simulation.WithAntLogSize(entries)(&cnf.GameConfiguration.SimulationConfig)
+	}
+}
+
 func WithRoles(roles ant.Roles, chooseRole ant.ChooseRole) (Option, error) {
 	roleProperties := make(map[string]simulation.Properties)
 	for roleName, role := range roles {
@@ -0,0 +1,146 @@
+/*
+Copyright (c) 2024 Sebastian Kroczek <me@xbug.de>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package external
+
+import (
+	"github.com/gotameme/core/ant"
+	"sort"
+	"sync"
+)
+
+// externalAnt forwards the callbacks of an ant to the bot and runs the commands of the answers.
+type externalAnt struct {
+	bot *Bot
+	os  ant.AntOs
+
+	// callbacks of an ant may run concurrently
+	mu     sync.Mutex
+	sugars map[int]ant.Sugar
+}
+
+func (a *externalAnt) Waits() {
+	a.send(Message{Event: "Waits"})
+}
+
+func (a *externalAnt) SeeSugar(sugar ant.Sugar) {
+	a.send(Message{Event: "SeeSugar", Sugar: a.sugarInfo(sugar)})
+}
+
+func (a *externalAnt) ReachedSugar(sugar ant.Sugar) {
+	a.send(Message{Event: "ReachedSugar", Sugar: a.sugarInfo(sugar)})
+}
+
+func (a *externalAnt) SeeMark(mark ant.Mark) {
+	a.send(Message{Event: "SeeMark", Mark: &MarkInfo{Information: mark.GetInformation()}})
+}
+
+func (a *externalAnt) Hear(from ant.FriendInfo, msg int) {
+	a.send(Message{
+		Event: "Hear",
+		Friend: &FriendInfo{
+			Id:        from.Id,
+			Role:      from.Role,
+			Load:      from.CurrentLoad,
+			Direction: from.Direction,
+			Distance:  from.Distance,
+		},
+		Message: &msg,
+	})
+}
+
+func (a *externalAnt) Tick() {
+	a.send(Message{Event: "Tick"})
+}
+
+func (a *externalAnt) sugarInfo(sugar ant.Sugar) *SugarInfo {
+	a.mu.Lock()
+	a.sugars[sugar.GetId()] = sugar
+	a.mu.Unlock()
+	return &SugarInfo{
+		Id:        sugar.GetId(),
+		Amount:    sugar.GetCurrentSugar(),
+		Direction: a.os.GetDirectionToSugar(sugar),
+	}
+}
+
+func (a *externalAnt) send(m Message) {
+	m.Type = "event"
+	m.Ant = &AntInfo{
+		Id:    a.os.GetId(),
+		Role:  a.os.GetRole(),
+		Load:  a.os.GetCurrentLoad(),
+		Order: a.os.GetOrder(),
+	}
+	commands, err := a.bot.request(m)
+	if err != nil {
+		panic(err)
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	for _, c := range commands {
+		switch c.Cmd {
+		case "GoForwards":
+			a.os.GoForwards()
+		case "GoForward":
+			a.os.GoForward(c.Value)
+		case "Turn":
+			a.os.Turn(c.Value)
+		case "GoToSugar":
+			if sugar, ok := a.sugars[c.Sugar]; ok {
+				a.os.GoToSugar(sugar)
+			}
+		case "TakeSugar":
+			if sugar, ok := a.sugars[c.Sugar]; ok {
+				_ = a.os.TakeSugar(sugar)
+			}
+		case "GoToAntHill":
+			a.os.GotToAntHill()
+		case "SetMark":
+			a.os.SetMark(c.Radius, c.Value)
+		case "Say":
+			_ = a.os.Say(c.Value)
+		case "Log":
+			a.os.Log(c.Message, logAttrs(c.Attrs)...)
+		}
+	}
+	// forget the sugar that is gone
+	for id, sugar := range a.sugars {
+		if sugar.GetCurrentSugar() <= 0 {
+			delete(a.sugars, id)
+		}
+	}
+}
+
+// logAttrs returns the attributes of a Log command as key-value pairs, sorted by key.
+func logAttrs(attrs map[string]string) []any {
+	keys := make([]string, 0, len(attrs))
+	for key := range attrs {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	args := make([]any, 0, 2*len(keys))
+	for _, key := range keys {
+		args = append(args, key, attrs[key])
+	}
+	return args
+}
@@ -0,0 +1,238 @@
+/*
+Copyright (c) 2024 Sebastian Kroczek <me@xbug.de>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package external
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/gotameme/core/ant"
+	"io"
+	"os"
+	"os/exec"
+	"sync"
+	"time"
+)
+
+const (
+	defaultTimeout          = time.Second
+	defaultHandshakeTimeout = 5 * time.Second
+	maxLineLength           = 1024 * 1024
+)
+
+// ErrClosed is returned for requests to a bot that has been closed.
+var ErrClosed = errors.New("external bot is closed")
+
+type Option func(*Bot)
+
+// WithTimeout defines how long the bot may take to answer an event.
+func WithTimeout(timeout time.Duration) Option {
+	return func(b *Bot) {
+		b.timeout = timeout
+	}
+}
+
+// WithHandshakeTimeout defines how long the bot may take to start and answer the hello message.
+func WithHandshakeTimeout(timeout time.Duration) Option {
+	return func(b *Bot) {
+		b.handshakeTimeout = timeout
+	}
+}
+
+// WithStderr redirects the stderr of the bot, by default it is written to the stderr of the engine.
+func WithStderr(w io.Writer) Option {
+	return func(b *Bot) {
+		b.stderr = w
+	}
+}
+
+// Bot is an external process that drives all ants of a colony. The process is started with the first ant.
+type Bot struct {
+	command          string
+	args             []string
+	timeout          time.Duration
+	handshakeTimeout time.Duration
+	stderr           io.Writer
+
+	mu    sync.Mutex
+	cmd   *exec.Cmd
+	stdin io.WriteCloser
+	lines chan []byte
+	// done is closed when the process is stopped, so the reader does not wait for a line nobody takes
+	done chan struct{}
+	err  error
+}
+
+func NewBot(command string, args []string, options ...Option) *Bot {
+	b := &Bot{
+		command:          command,
+		args:             args,
+		timeout:          defaultTimeout,
+		handshakeTimeout: defaultHandshakeTimeout,
+		stderr:           os.Stderr,
+	}
+	for _, option := range options {
+		option(b)
+	}
+	return b
+}
+
+// AntConstructor returns a constructor for ants driven by the bot.
+// The callbacks of these ants panic if the bot fails, so the fault policy of the simulation applies.
+func (b *Bot) AntConstructor() ant.AntConstructor {
+	return func(antOs ant.AntOs) interface{} {
+		a := &externalAnt{bot: b, os: antOs, sugars: make(map[int]ant.Sugar)}
+		a.send(Message{Event: "New"})
+		return a
+	}
+}
+
+// start runs the process and does the handshake, the caller must hold the lock.
+func (b *Bot) start() error {
+	cmd := exec.Command(b.command, b.args...)
+	cmd.Stderr = b.stderr
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	if err = cmd.Start(); err != nil {
+		return err
+	}
+	b.cmd, b.stdin, b.lines, b.done = cmd, stdin, make(chan []byte), make(chan struct{})
+	go func(lines chan<- []byte, done <-chan struct{}) {
+		defer close(lines)
+		scanner := bufio.NewScanner(stdout)
+		scanner.Buffer(make([]byte, 4096), maxLineLength)
+		for scanner.Scan() {
+			select {
+			case lines <- append([]byte(nil), scanner.Bytes()...):
+			case <-done:
+				return
+			}
+		}
+	}(b.lines, b.done)
+
+	hello, err := b.roundTrip(Message{Type: "hello", Version: ProtocolVersion}, b.handshakeTimeout)
+	if err != nil {
+		return fmt.Errorf("handshake failed: %w", err)
+	}
+	if hello.Type != "hello" || hello.Version != ProtocolVersion {
+		return fmt.Errorf("handshake failed: expected hello with protocol version %d, got %s with version %d",
+			ProtocolVersion, hello.Type, hello.Version)
+	}
+	return nil
+}
+
+// roundTrip sends a message and waits for the answer, the caller must hold the lock. The timeout covers writing the
+// message as well, a bot that stops reading fails like a bot that stops answering.
+func (b *Bot) roundTrip(m Message, timeout time.Duration) (Message, error) {
+	line, err := json.Marshal(m)
+	if err != nil {
+		return Message{}, err
+	}
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	// the write ends with an error once the process is stopped, so it does not outlive a timeout for long
+	written := make(chan error, 1)
+	go func(stdin io.Writer) {
+		_, err := stdin.Write(append(line, '\n'))
+		written <- err
+	}(b.stdin)
+	select {
+	case err = <-written:
+		if err != nil {
+			return Message{}, err
+		}
+	case <-timer.C:
+		return Message{}, fmt.Errorf("bot did not read the message within %s", timeout)
+	}
+	select {
+	case line, ok := <-b.lines:
+		if !ok {
+			return Message{}, errors.New("bot closed its output")
+		}
+		var answer Message
+		if err = json.Unmarshal(line, &answer); err != nil {
+			return Message{}, fmt.Errorf("invalid message %q: %w", line, err)
+		}
+		return answer, nil
+	case <-timer.C:
+		return Message{}, fmt.Errorf("no answer within %s", timeout)
+	}
+}
+
+// request sends an event and returns the commands of the answer. After the first error the bot is stopped and
+// every further request fails with the same error.
+func (b *Bot) request(m Message) ([]Command, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.err != nil {
+		return nil, b.err
+	}
+	if b.cmd == nil {
+		if err := b.start(); err != nil {
+			return nil, b.fail(err)
+		}
+	}
+	answer, err := b.roundTrip(m, b.timeout)
+	if err != nil {
+		return nil, b.fail(err)
+	}
+	if answer.Type != "commands" {
+		return nil, b.fail(fmt.Errorf("expected commands, got %q", answer.Type))
+	}
+	return answer.Commands, nil
+}
+
+func (b *Bot) fail(err error) error {
+	b.err = fmt.Errorf("external bot %s: %w", b.command, err)
+	b.kill()
+	return b.err
+}
+
+func (b *Bot) kill() {
+	if b.cmd == nil {
+		return
+	}
+	close(b.done)
+	_ = b.stdin.Close()
+	_ = b.cmd.Process.Kill()
+	_ = b.cmd.Wait()
+	b.cmd = nil
+}
+
+// Close stops the process of the bot. It is safe to call Close more than once.
+func (b *Bot) Close() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.err == nil {
+		b.err = ErrClosed
+	}
+	b.kill()
+	return nil
+}
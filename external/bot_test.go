@@ -0,0 +1,162 @@
+/*
+Copyright (c) 2024 Sebastian Kroczek <me@xbug.de>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package external
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"github.com/gotameme/core/ant"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testBotEnv makes the test binary act as a bot, its value is the behaviour of the bot.
+const testBotEnv = "EXTERNAL_TEST_BOT"
+
+var echobot string
+
+func TestMain(m *testing.M) {
+	if mode := os.Getenv(testBotEnv); mode != "" {
+		runTestBot(mode)
+		return
+	}
+	dir, err := os.MkdirTemp("", "echobot")
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	echobot = filepath.Join(dir, "echobot")
+	if runtime.GOOS == "windows" {
+		echobot += ".exe"
+	}
+	build := exec.Command("go", "build", "-o", echobot, "./echobot")
+	build.Stderr = os.Stderr
+	if err = build.Run(); err != nil {
+		fmt.Fprintln(os.Stderr, "build echobot:", err)
+		os.Exit(1)
+	}
+	code := m.Run()
+	_ = os.RemoveAll(dir)
+	os.Exit(code)
+}
+
+// runTestBot answers the hello message and then, depending on mode, stops reading or answering.
+func runTestBot(mode string) {
+	in := bufio.NewScanner(os.Stdin)
+	in.Buffer(make([]byte, 4096), maxLineLength)
+	if !in.Scan() {
+		return
+	}
+	_ = json.NewEncoder(os.Stdout).Encode(Message{Type: "hello", Version: ProtocolVersion})
+	switch mode {
+	case "deaf":
+		// stop reading, writes to the bot block once the pipe is full
+		time.Sleep(time.Minute)
+	case "silent":
+		// read but never answer
+		for in.Scan() {
+		}
+	}
+}
+
+func testBot(t *testing.T, mode string, options ...Option) *Bot {
+	t.Helper()
+	// the bot inherits the environment
+	t.Setenv(testBotEnv, mode)
+	b := NewBot(os.Args[0], []string{"-test.run=^$"}, options...)
+	t.Cleanup(func() { _ = b.Close() })
+	return b
+}
+
+// antOs records the calls of the commands of the bot.
+type antOs struct {
+	calls []string
+}
+
+func (a *antOs) GetId() int                        { return 1 }
+func (a *antOs) GetRole() string                   { return "" }
+func (a *antOs) GetCurrentLoad() int               { return 0 }
+func (a *antOs) GetDirectionToSugar(ant.Sugar) int { return 0 }
+func (a *antOs) GoForwards()                       { a.calls = append(a.calls, "GoForwards") }
+func (a *antOs) GoForward(int)                     { a.calls = append(a.calls, "GoForward") }
+func (a *antOs) Turn(int)                          { a.calls = append(a.calls, "Turn") }
+func (a *antOs) GoToSugar(ant.Sugar)               { a.calls = append(a.calls, "GoToSugar") }
+func (a *antOs) TakeSugar(ant.Sugar) error         { a.calls = append(a.calls, "TakeSugar"); return nil }
+func (a *antOs) GotToAntHill()                     { a.calls = append(a.calls, "GoToAntHill") }
+func (a *antOs) SetMark(int, int)                  { a.calls = append(a.calls, "SetMark") }
+func (a *antOs) Colony() ant.Colony                { return nil }
+func (a *antOs) GetOrder() int                     { return 0 }
+func (a *antOs) Say(int) error                     { a.calls = append(a.calls, "Say"); return nil }
+func (a *antOs) Log(string, ...any)                { a.calls = append(a.calls, "Log") }
+
+func TestEchoBot(t *testing.T) {
+	bot := NewBot(echobot, nil)
+	defer bot.Close()
+	fake := &antOs{}
+	a := bot.AntConstructor()(fake).(interface{ Waits() })
+	a.Waits()
+	if want := []string{"Turn", "GoForward"}; !reflect.DeepEqual(fake.calls, want) {
+		t.Errorf("commands %v, want %v", fake.calls, want)
+	}
+	if err := bot.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := bot.request(Message{Type: "event", Event: "Waits"}); err != ErrClosed {
+		t.Errorf("request after Close returned %v, want %v", err, ErrClosed)
+	}
+}
+
+func TestBotTimeout(t *testing.T) {
+	tests := []struct {
+		mode string
+		// role makes the message larger than the buffer of a pipe
+		role string
+		want string
+	}{
+		{"silent", "", "no answer within"},
+		{"deaf", strings.Repeat("x", maxLineLength/2), "did not read the message within"},
+	}
+	for _, test := range tests {
+		t.Run(test.mode, func(t *testing.T) {
+			bot := testBot(t, test.mode, WithTimeout(200*time.Millisecond))
+			start := time.Now()
+			_, err := bot.request(Message{Type: "event", Event: "Waits", Ant: &AntInfo{Role: test.role}})
+			if err == nil || !strings.Contains(err.Error(), test.want) {
+				t.Fatalf("error %v, want %q", err, test.want)
+			}
+			if elapsed := time.Since(start); elapsed > 5*time.Second {
+				t.Errorf("request took %s", elapsed)
+			}
+			if _, again := bot.request(Message{Type: "event", Event: "Tick"}); again != err {
+				t.Errorf("second request returned %v, want the first error", again)
+			}
+		})
+	}
+}
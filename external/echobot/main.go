@@ -0,0 +1,76 @@
+/*
+Copyright (c) 2024 Sebastian Kroczek <me@xbug.de>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+// Echobot is a tiny bot for the external protocol, it is meant to test the adapter and as a template for bots in
+// other languages. It wanders around, collects the sugar it sees and brings it home.
+//
+//	go run ./external/echobot
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"github.com/gotameme/core/external"
+	"log"
+	"math/rand"
+	"os"
+)
+
+func main() {
+	in := bufio.NewScanner(os.Stdin)
+	out := json.NewEncoder(os.Stdout)
+	for in.Scan() {
+		var m external.Message
+		if err := json.Unmarshal(in.Bytes(), &m); err != nil {
+			log.Fatal(err)
+		}
+		var answer external.Message
+		if m.Type == "hello" {
+			answer = external.Message{Type: "hello", Version: external.ProtocolVersion}
+		} else {
+			answer = external.Message{Type: "commands", Commands: commands(m)}
+		}
+		if err := out.Encode(answer); err != nil {
+			log.Fatal(err)
+		}
+	}
+}
+
+func commands(m external.Message) []external.Command {
+	switch m.Event {
+	case "Waits":
+		return []external.Command{
+			{Cmd: "Turn", Value: rand.Intn(360)},
+			{Cmd: "GoForward", Value: 50 + rand.Intn(100)},
+		}
+	case "SeeSugar":
+		if m.Ant.Load == 0 {
+			return []external.Command{{Cmd: "GoToSugar", Sugar: m.Sugar.Id}}
+		}
+	case "ReachedSugar":
+		return []external.Command{
+			{Cmd: "TakeSugar", Sugar: m.Sugar.Id},
+			{Cmd: "GoToAntHill"},
+		}
+	}
+	return nil
+}
@@ -0,0 +1,94 @@
+/*
+Copyright (c) 2024 Sebastian Kroczek <me@xbug.de>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+// Package external drives ants by an external process that speaks a line-delimited JSON protocol on stdin and stdout.
+//
+// The engine starts the protocol by sending a hello message, which the process has to answer with a hello message
+// of the same version:
+//
+//	{"type":"hello","version":1}
+//
+// Afterward the engine sends one event message per callback of an ant and waits for exactly one commands message:
+//
+//	{"type":"event","event":"SeeSugar","ant":{"id":3,"role":"","load":0,"order":0},"sugar":{"id":7,"amount":990,"direction":45}}
+//	{"type":"commands","commands":[{"cmd":"GoToSugar","sugar":7}]}
+//
+// The events are New, Waits, SeeSugar, ReachedSugar, SeeMark, Hear and Tick. The commands are GoForwards,
+// GoForward, Turn, GoToSugar, TakeSugar, GoToAntHill, SetMark, Say and Log, they mirror the methods of ant.AntOs.
+// Sugar is referenced by its id, only sugar the ant has seen or reached can be referenced.
+package external
+
+// ProtocolVersion is the version of the protocol spoken by this package.
+const ProtocolVersion = 1
+
+// Message is a single line of the protocol.
+type Message struct {
+	Type     string      `json:"type"`
+	Version  int         `json:"version,omitempty"`
+	Event    string      `json:"event,omitempty"`
+	Ant      *AntInfo    `json:"ant,omitempty"`
+	Sugar    *SugarInfo  `json:"sugar,omitempty"`
+	Mark     *MarkInfo   `json:"mark,omitempty"`
+	Friend   *FriendInfo `json:"friend,omitempty"`
+	Message  *int        `json:"message,omitempty"`
+	Commands []Command   `json:"commands,omitempty"`
+}
+
+// AntInfo describes the ant an event is sent for.
+type AntInfo struct {
+	Id    int    `json:"id"`
+	Role  string `json:"role"`
+	Load  int    `json:"load"`
+	Order int    `json:"order"`
+}
+
+// SugarInfo describes the sugar of a SeeSugar or ReachedSugar event.
+type SugarInfo struct {
+	Id        int `json:"id"`
+	Amount    int `json:"amount"`
+	Direction int `json:"direction"`
+}
+
+// MarkInfo describes the mark of a SeeMark event.
+type MarkInfo struct {
+	Information int `json:"information"`
+}
+
+// FriendInfo describes the sender of a Hear event.
+type FriendInfo struct {
+	Id        int    `json:"id"`
+	Role      string `json:"role"`
+	Load      int    `json:"load"`
+	Direction int    `json:"direction"`
+	Distance  int    `json:"distance"`
+}
+
+// Command is an ant.AntOs call requested by the external process.
+type Command struct {
+	Cmd    string `json:"cmd"`
+	Value  int    `json:"value,omitempty"`  // steps of GoForward, direction of Turn, information of SetMark and message of Say
+	Radius int    `json:"radius,omitempty"` // radius of SetMark
+	Sugar  int    `json:"sugar,omitempty"`  // sugar id of GoToSugar and TakeSugar
+	// Message and Attrs are the message and the attributes of Log
+	Message string            `json:"message,omitempty"`
+	Attrs   map[string]string `json:"attrs,omitempty"`
+}
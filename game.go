@@ -24,32 +24,86 @@ package core
 import (
 	"errors"
 	"github.com/gotameme/core/internal"
+	"github.com/gotameme/core/internal/render"
 	"github.com/gotameme/core/internal/simulation"
 	"github.com/hajimehoshi/ebiten/v2"
 )
 
-func Run(options ...Option) {
+// Run runs the game, or the bare simulation if Headless is set, until it is quit or fails.
+// A failing ant ends the run with a *Fault error, depending on the FaultPolicy.
+// Errors writing the outputs of the run, like the GIF or the heatmap, are returned if the run itself did not fail.
+func Run(options ...Option) (result *Result, err error) {
 	cnf := NewConfiguration(options...)
-	Options(options).Apply(cnf)
+	defer func() {
+		if closeErr := cnf.close(); err == nil {
+			err = closeErr
+		}
+	}()
+	if cnf.err != nil {
+		return nil, cnf.err
+	}
+	if cnf.control != nil {
+		if err := cnf.control.Listen(); err != nil {
+			return nil, err
+		}
+	}
+	if cnf.terminal != nil {
+		if err := cnf.terminal.Open(); err != nil {
+			return nil, err
+		}
+	}
+	var recorder *render.GIF
+	if cnf.gif != "" {
+		// the GIF is created once all options are applied, it plays at the final TPS
+		recorder = render.NewGIF(cnf.gif, cnf.gifInterval, cnf.TPS)
+		simulation.WithTickRecorder(recorder)(&cnf.GameConfiguration.SimulationConfig)
+	}
+	var s *simulation.Simulation
 	if cnf.headless != true {
 		ebiten.SetWindowSize(cnf.ScreenWidth, cnf.ScreenHeight)
 		ebiten.SetWindowTitle("Go! Tame! Me!")
-		ebiten.SetTPS(60)
-		if err := ebiten.RunGame(internal.NewGame(cnf.ScreenWidth, cnf.ScreenHeight, cnf.GameConfiguration)); err != nil {
-			if !errors.Is(err, internal.Quit) {
-				panic(err)
+		// the interface runs at a fixed rate, the game runs as many ticks per update as the TPS ask for
+		ebiten.SetTPS(internal.UITPS)
+		game := internal.NewGame(cnf.ScreenWidth, cnf.ScreenHeight, cnf.GameConfiguration)
+		s = game.Simulation()
+		if cnf.restore != "" {
+			if err = restoreSimulation(s, cnf.restore); err != nil {
+				return nil, err
 			}
 		}
+		err = ebiten.RunGame(game)
 	} else {
-		s := simulation.NewSimulation(cnf.ScreenWidth, cnf.ScreenHeight, cnf.SimulationConfig)
-		for {
-			if err := s.Update(); err != nil {
-				if !errors.Is(err, internal.Quit) {
-					panic(err)
-				}
-				break
+		s = simulation.NewSimulation(cnf.ScreenWidth, cnf.ScreenHeight, cnf.SimulationConfig)
+		if cnf.restore != "" {
+			if err = restoreSimulation(s, cnf.restore); err != nil {
+				return nil, err
 			}
 		}
+		err = newHeadlessLoop(cnf).run(s)
+	}
+	if errors.Is(err, internal.Quit) {
+		err = nil
 	}
+	if cnf.heatmap != "" {
+		if heatmapErr := writeHeatmap(s.Heatmap(), cnf.heatmap); err == nil {
+			err = heatmapErr
+		}
+	}
+	if recorder != nil {
+		if gifErr := recorder.Close(); err == nil {
+			err = gifErr
+		}
+	}
+	return newResult(s), err
+}
 
+// close closes every closer and returns the first error.
+func (cnf *Configuration) close() error {
+	var first error
+	for _, closer := range cnf.closers {
+		if err := closer.Close(); first == nil {
+			first = err
+		}
+	}
+	return first
 }
@@ -5,7 +5,9 @@ go 1.22.0
 require (
 	github.com/hajimehoshi/ebiten/v2 v2.6.6
 	github.com/paulmach/orb v0.11.1
+	github.com/tetratelabs/wazero v1.9.0
 	github.com/tidwall/rtree v1.10.0
+	go.starlark.net v0.0.0-20260210143700-b62fd896b91b
 )
 
 require (
@@ -0,0 +1,135 @@
+/*
+Copyright (c) 2024 Sebastian Kroczek <me@xbug.de>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package core
+
+import (
+	"github.com/gotameme/core/internal"
+	"github.com/gotameme/core/internal/control"
+	"github.com/gotameme/core/internal/simulation"
+	"time"
+)
+
+// headlessLoop runs a simulation without a window. It can be paused, stepped, sped up and stopped by the
+// commands of the control server and the keys of the terminal renderer.
+type headlessLoop struct {
+	cnf     *Configuration
+	paused  bool
+	speed   float64
+	steps   int
+	remotes []internal.Remote
+	// commands merges the commands of the remotes
+	commands chan control.Command
+}
+
+func newHeadlessLoop(cnf *Configuration) *headlessLoop {
+	l := &headlessLoop{cnf: cnf, speed: 1, commands: make(chan control.Command)}
+	if cnf.control != nil {
+		l.remotes = append(l.remotes, cnf.control)
+	}
+	if cnf.terminal != nil {
+		l.remotes = append(l.remotes, cnf.terminal)
+	}
+	return l
+}
+
+// run updates the simulation until it fails, the ticks are done or it is stopped.
+func (l *headlessLoop) run(s *simulation.Simulation) error {
+	done := make(chan struct{})
+	defer close(done)
+	for _, remote := range l.remotes {
+		go l.forward(remote, done)
+	}
+	next := time.Now()
+	for tick := 0; l.cnf.ticks == 0 || tick < l.cnf.ticks; tick++ {
+		if stop := l.handleCommands(); stop {
+			return nil
+		}
+		if l.steps > 0 {
+			l.steps--
+		}
+		if l.cnf.throttled {
+			interval := time.Duration(float64(time.Second) / (float64(l.cnf.TPS) * l.speed))
+			// do not catch up on the time spent paused
+			if next = next.Add(interval); time.Until(next) < -interval {
+				next = time.Now()
+			}
+			time.Sleep(time.Until(next))
+		}
+		if err := s.Update(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// forward passes the commands of the remote to the loop until the run is done.
+func (l *headlessLoop) forward(remote internal.Remote, done <-chan struct{}) {
+	for {
+		select {
+		case command := <-remote.Commands():
+			select {
+			case l.commands <- command:
+			case <-done:
+				return
+			}
+		case <-done:
+			return
+		}
+	}
+}
+
+// handleCommands applies the pending commands, while paused it waits for a command that lets a tick run.
+func (l *headlessLoop) handleCommands() (stop bool) {
+	if len(l.remotes) == 0 {
+		return false
+	}
+	for {
+		for _, remote := range l.remotes {
+			remote.Report(l.paused, l.speed)
+		}
+		var command control.Command
+		if l.paused && l.steps == 0 {
+			command = <-l.commands
+		} else {
+			select {
+			case command = <-l.commands:
+			default:
+				return false
+			}
+		}
+		switch command.Kind {
+		case control.Pause:
+			l.paused = true
+		case control.Resume:
+			l.paused = false
+			l.steps = 0
+		case control.Step:
+			l.paused = true
+			l.steps += command.Ticks
+		case control.SetSpeed:
+			l.speed = control.ClampSpeed(command.Speed)
+		case control.Stop:
+			return true
+		}
+	}
+}
@@ -0,0 +1,83 @@
+/*
+Copyright (c) 2024 Sebastian Kroczek <me@xbug.de>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package core
+
+import (
+	"github.com/gotameme/core/internal/simulation"
+	"image"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// WithHeatmap defines the grid of the heatmap, cells of cellSize pixels, and whether it keeps the ants apart by role
+// or by load. The heatmap is shown with F7 and written by WithHeatmapPNG.
+func WithHeatmap(cellSize int, split HeatmapSplit) Option {
+	if cellSize <= 0 {
+		panic("Heatmap cell size must be greater than 0")
+	}
+	return func(cnf *Configuration) {
+		simulation.WithHeatmap(cellSize, split)(&cnf.GameConfiguration.SimulationConfig)
+	}
+}
+
+// WithHeatmapPNG writes the heatmap to path when the run ends. If the heatmap is split, every layer is written next
+// to it as well, the name of the layer appended to the file name, e.g. heatmap-loaded.png.
+func WithHeatmapPNG(path string) Option {
+	return func(cnf *Configuration) {
+		cnf.heatmap = path
+	}
+}
+
+func writeHeatmap(h *simulation.Heatmap, path string) error {
+	if err := writePNG(h.Image(), h.CellSize(), path); err != nil {
+		return err
+	}
+	layers := h.Layers()
+	if len(layers) < 2 {
+		return nil
+	}
+	ext := filepath.Ext(path)
+	for _, layer := range layers {
+		name := layer
+		if name == "" {
+			name = "default"
+		}
+		if err := writePNG(h.LayerImage(layer), h.CellSize(), strings.TrimSuffix(path, ext)+"-"+name+ext); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writePNG(img *image.RGBA, cellSize int, path string) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err = simulation.EncodePNG(file, img, cellSize); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
@@ -0,0 +1,283 @@
+/*
+Copyright (c) 2024 Sebastian Kroczek <me@xbug.de>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+// Package control serves the status of a running simulation over HTTP and takes commands to pause, resume,
+// step, change the speed and stop it.
+//
+//	GET  /stats         status as JSON
+//	GET  /metrics       status in the Prometheus text format
+//	GET  /snapshot.png  picture of the world
+//	POST /pause, /resume, /stop
+//	POST /step?ticks=N  pause and step N ticks, 1 by default and at most MaxStep
+//	POST /speed?factor=F  F is clamped to MinSpeed and MaxSpeed
+package control
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/gotameme/core/ant"
+	"github.com/gotameme/core/internal/render"
+	"github.com/gotameme/core/internal/simulation"
+	"image/png"
+	"math"
+	"net"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+)
+
+type CommandKind int
+
+const (
+	Pause CommandKind = iota
+	Resume
+	Step
+	SetSpeed
+	Stop
+)
+
+// MinSpeed and MaxSpeed bound the speed factor, like the speeds of the game.
+const (
+	MinSpeed = 0.25
+	MaxSpeed = 16
+)
+
+// MaxStep is the largest number of ticks a single step may ask for.
+const MaxStep = 10_000
+
+// ClampSpeed keeps a speed factor between MinSpeed and MaxSpeed.
+func ClampSpeed(speed float64) float64 {
+	return math.Min(math.Max(speed, MinSpeed), MaxSpeed)
+}
+
+// Command is sent to the loop that runs the simulation.
+type Command struct {
+	Kind  CommandKind
+	Ticks int     // for Step
+	Speed float64 // for SetSpeed
+}
+
+// Status is the state of the run, it is published by the loop that runs the simulation.
+type Status struct {
+	Tick       int                   `json:"tick"`
+	Seed       uint64                `json:"seed"`
+	Paused     bool                  `json:"paused"`
+	Speed      float64               `json:"speed"`
+	Colony     ant.ColonyStats       `json:"colony"`
+	Statistics simulation.Statistics `json:"statistics"`
+}
+
+// Server is a simulation.TickRecorder that keeps the status of the last tick for its handler.
+type Server struct {
+	addr     string
+	commands chan Command
+
+	mu     sync.RWMutex
+	status Status
+	state  simulation.State
+	width  int
+	height int
+
+	server *http.Server
+}
+
+// New returns a server for addr, a missing host means localhost.
+func New(addr string) *Server {
+	if host, port, err := net.SplitHostPort(addr); err == nil && host == "" {
+		addr = net.JoinHostPort("localhost", port)
+	}
+	return &Server{
+		addr:     addr,
+		commands: make(chan Command, 16),
+		status:   Status{Speed: 1},
+	}
+}
+
+// Listen starts serving in the background.
+func (s *Server) Listen() error {
+	listener, err := net.Listen("tcp", s.addr)
+	if err != nil {
+		return err
+	}
+	s.server = &http.Server{Handler: s.Handler()}
+	go func() {
+		_ = s.server.Serve(listener)
+	}()
+	return nil
+}
+
+func (s *Server) Close() error {
+	if s.server == nil {
+		return nil
+	}
+	err := s.server.Shutdown(context.Background())
+	s.server = nil
+	return err
+}
+
+// Commands are the commands sent to the simulation, the loop that runs it has to apply them.
+func (s *Server) Commands() <-chan Command {
+	return s.commands
+}
+
+// Report publishes whether the loop is paused and how fast it runs.
+func (s *Server) Report(paused bool, speed float64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.status.Paused = paused
+	s.status.Speed = speed
+}
+
+func (s *Server) RecordTick(sim *simulation.Simulation) error {
+	status := Status{
+		Tick:       sim.Tick(),
+		Seed:       sim.Seed(),
+		Colony:     sim.ColonyStats(),
+		Statistics: sim.Statistics(),
+	}
+	state := sim.State()
+	width, height := sim.Layout(0, 0)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	status.Paused, status.Speed = s.status.Paused, s.status.Speed
+	s.status, s.state, s.width, s.height = status, state, width, height
+	return nil
+}
+
+// region Handler
+
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/stats", s.handleStats)
+	mux.HandleFunc("/metrics", s.handleMetrics)
+	mux.HandleFunc("/snapshot.png", s.handleSnapshot)
+	mux.HandleFunc("/pause", s.handleCommand(func(*http.Request) (Command, error) {
+		return Command{Kind: Pause}, nil
+	}))
+	mux.HandleFunc("/resume", s.handleCommand(func(*http.Request) (Command, error) {
+		return Command{Kind: Resume}, nil
+	}))
+	mux.HandleFunc("/stop", s.handleCommand(func(*http.Request) (Command, error) {
+		return Command{Kind: Stop}, nil
+	}))
+	mux.HandleFunc("/step", s.handleCommand(func(r *http.Request) (Command, error) {
+		ticks := 1
+		if value := r.URL.Query().Get("ticks"); value != "" {
+			var err error
+			if ticks, err = strconv.Atoi(value); err != nil || ticks <= 0 || ticks > MaxStep {
+				return Command{}, fmt.Errorf("ticks must be a number from 1 to %d", MaxStep)
+			}
+		}
+		return Command{Kind: Step, Ticks: ticks}, nil
+	}))
+	mux.HandleFunc("/speed", s.handleCommand(func(r *http.Request) (Command, error) {
+		speed, err := strconv.ParseFloat(r.URL.Query().Get("factor"), 64)
+		if err != nil || speed <= 0 || math.IsNaN(speed) || math.IsInf(speed, 0) {
+			return Command{}, errors.New("factor must be a positive number")
+		}
+		return Command{Kind: SetSpeed, Speed: ClampSpeed(speed)}, nil
+	}))
+	return mux
+}
+
+func (s *Server) handleStats(w http.ResponseWriter, r *http.Request) {
+	s.mu.RLock()
+	status := s.status
+	s.mu.RUnlock()
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(status)
+}
+
+func (s *Server) handleMetrics(w http.ResponseWriter, r *http.Request) {
+	s.mu.RLock()
+	status := s.status
+	s.mu.RUnlock()
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	gauge := func(name, help string, value float64) {
+		fmt.Fprintf(w, "# HELP gotameme_%s %s\n# TYPE gotameme_%s gauge\ngotameme_%s %g\n", name, help, name, name, value)
+	}
+	gauge("tick", "Current tick.", float64(status.Tick))
+	gauge("paused", "1 if the simulation is paused.", boolValue(status.Paused))
+	gauge("speed", "Speed factor of the simulation.", status.Speed)
+	gauge("ants", "Number of ants.", float64(status.Colony.Ants))
+	gauge("idle_ants", "Number of ants without a state.", float64(status.Colony.IdleAnts))
+	gauge("hill_sugar", "Sugar in the hill.", float64(status.Colony.Sugar))
+	gauge("sugar_in_transit", "Sugar carried by ants.", float64(status.Colony.SugarInTransit))
+	gauge("marks", "Number of active marks.", float64(status.Colony.Marks))
+	gauge("ants_lost", "Number of ants that were removed.", float64(status.Statistics.AntsLost))
+	roles := make([]string, 0, len(status.Colony.RolesCount))
+	for role := range status.Colony.RolesCount {
+		roles = append(roles, role)
+	}
+	sort.Strings(roles)
+	if len(roles) > 0 {
+		fmt.Fprint(w, "# HELP gotameme_role_ants Number of ants per role.\n# TYPE gotameme_role_ants gauge\n")
+	}
+	for _, role := range roles {
+		fmt.Fprintf(w, "gotameme_role_ants{role=%q} %d\n", role, status.Colony.RolesCount[role])
+	}
+}
+
+func boolValue(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+func (s *Server) handleSnapshot(w http.ResponseWriter, r *http.Request) {
+	s.mu.RLock()
+	state, width, height := s.state, s.width, s.height
+	s.mu.RUnlock()
+	if width == 0 {
+		http.Error(w, "no tick yet", http.StatusServiceUnavailable)
+		return
+	}
+	w.Header().Set("Content-Type", "image/png")
+	_ = png.Encode(w, render.Render(state, width, height))
+}
+
+func (s *Server) handleCommand(parse func(*http.Request) (Command, error)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		command, err := parse(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		select {
+		case s.commands <- command:
+			w.WriteHeader(http.StatusAccepted)
+		default:
+			http.Error(w, "too many pending commands", http.StatusServiceUnavailable)
+		}
+	}
+}
+
+// endregion
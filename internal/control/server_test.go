@@ -0,0 +1,223 @@
+/*
+Copyright (c) 2024 Sebastian Kroczek <me@xbug.de>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package control
+
+import (
+	"encoding/json"
+	"github.com/gotameme/core/ant"
+	"github.com/gotameme/core/internal/simulation"
+	"image/png"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// newTestServer returns a server that already recorded a tick and an HTTP server for its handler.
+func newTestServer(t *testing.T) (*Server, *httptest.Server) {
+	t.Helper()
+	s := New(":0")
+	s.status = Status{
+		Tick:   42,
+		Seed:   7,
+		Speed:  1,
+		Colony: ant.ColonyStats{Tick: 42, Ants: 3, RolesCount: ant.RolesCount{"scout": 2, "worker": 1}, Sugar: 5},
+	}
+	s.state = simulation.State{
+		Tick:  42,
+		Hill:  simulation.HillState{X: 40, Y: 30},
+		Ants:  []simulation.AntState{{Id: 1, X: 10, Y: 10}},
+		Sugar: []simulation.SugarState{{Id: 1, X: 60, Y: 40, Amount: 500}},
+	}
+	s.width, s.height = 80, 60
+	server := httptest.NewServer(s.Handler())
+	t.Cleanup(server.Close)
+	return s, server
+}
+
+func post(t *testing.T, url string) *http.Response {
+	t.Helper()
+	resp, err := http.Post(url, "", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { resp.Body.Close() })
+	return resp
+}
+
+func get(t *testing.T, url string) *http.Response {
+	t.Helper()
+	resp, err := http.Get(url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { resp.Body.Close() })
+	return resp
+}
+
+func TestStats(t *testing.T) {
+	_, server := newTestServer(t)
+	resp := get(t, server.URL+"/stats")
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status %d", resp.StatusCode)
+	}
+	var status Status
+	if err := json.NewDecoder(resp.Body).Decode(&status); err != nil {
+		t.Fatal(err)
+	}
+	if status.Tick != 42 || status.Seed != 7 || status.Colony.Ants != 3 {
+		t.Errorf("unexpected status %+v", status)
+	}
+}
+
+func TestMetrics(t *testing.T) {
+	_, server := newTestServer(t)
+	resp := get(t, server.URL+"/metrics")
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status %d", resp.StatusCode)
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"gotameme_tick 42\n", "gotameme_ants 3\n", `gotameme_role_ants{role="scout"} 2`} {
+		if !strings.Contains(string(body), want) {
+			t.Errorf("metrics miss %q:\n%s", want, body)
+		}
+	}
+}
+
+func TestSnapshot(t *testing.T) {
+	_, server := newTestServer(t)
+	resp := get(t, server.URL+"/snapshot.png")
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status %d", resp.StatusCode)
+	}
+	img, err := png.Decode(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if img.Bounds().Dx() != 80 || img.Bounds().Dy() != 60 {
+		t.Errorf("snapshot is %v", img.Bounds())
+	}
+}
+
+func TestSnapshotBeforeFirstTick(t *testing.T) {
+	server := httptest.NewServer(New(":0").Handler())
+	defer server.Close()
+	if resp := get(t, server.URL+"/snapshot.png"); resp.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("status %d, want %d", resp.StatusCode, http.StatusServiceUnavailable)
+	}
+}
+
+func TestCommands(t *testing.T) {
+	tests := []struct {
+		path string
+		want Command
+	}{
+		{"/pause", Command{Kind: Pause}},
+		{"/resume", Command{Kind: Resume}},
+		{"/stop", Command{Kind: Stop}},
+		{"/step", Command{Kind: Step, Ticks: 1}},
+		{"/step?ticks=5", Command{Kind: Step, Ticks: 5}},
+		{"/step?ticks=10000", Command{Kind: Step, Ticks: MaxStep}},
+		{"/speed?factor=2", Command{Kind: SetSpeed, Speed: 2}},
+		{"/speed?factor=1000", Command{Kind: SetSpeed, Speed: MaxSpeed}},
+		{"/speed?factor=0.001", Command{Kind: SetSpeed, Speed: MinSpeed}},
+	}
+	for _, test := range tests {
+		t.Run(test.path, func(t *testing.T) {
+			s, server := newTestServer(t)
+			if resp := post(t, server.URL+test.path); resp.StatusCode != http.StatusAccepted {
+				t.Fatalf("status %d", resp.StatusCode)
+			}
+			if command := <-s.Commands(); command != test.want {
+				t.Errorf("command %+v, want %+v", command, test.want)
+			}
+		})
+	}
+}
+
+func TestBadCommands(t *testing.T) {
+	paths := []string{
+		"/step?ticks=0",
+		"/step?ticks=-1",
+		"/step?ticks=x",
+		"/step?ticks=100000000",
+		"/speed",
+		"/speed?factor=0",
+		"/speed?factor=-1",
+		"/speed?factor=NaN",
+		"/speed?factor=Inf",
+		"/speed?factor=-Inf",
+		"/speed?factor=x",
+	}
+	for _, path := range paths {
+		t.Run(path, func(t *testing.T) {
+			s, server := newTestServer(t)
+			if resp := post(t, server.URL+path); resp.StatusCode != http.StatusBadRequest {
+				t.Errorf("status %d, want %d", resp.StatusCode, http.StatusBadRequest)
+			}
+			if len(s.Commands()) != 0 {
+				t.Errorf("a bad request sent a command")
+			}
+		})
+	}
+}
+
+func TestCommandsNeedPost(t *testing.T) {
+	_, server := newTestServer(t)
+	resp := get(t, server.URL+"/pause")
+	if resp.StatusCode != http.StatusMethodNotAllowed {
+		t.Errorf("status %d, want %d", resp.StatusCode, http.StatusMethodNotAllowed)
+	}
+	if allow := resp.Header.Get("Allow"); allow != http.MethodPost {
+		t.Errorf("Allow is %q", allow)
+	}
+}
+
+func TestTooManyCommands(t *testing.T) {
+	s, server := newTestServer(t)
+	for i := 0; i < cap(s.commands); i++ {
+		if resp := post(t, server.URL+"/pause"); resp.StatusCode != http.StatusAccepted {
+			t.Fatalf("command %d: status %d", i, resp.StatusCode)
+		}
+	}
+	if resp := post(t, server.URL+"/pause"); resp.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("status %d, want %d", resp.StatusCode, http.StatusServiceUnavailable)
+	}
+}
+
+func TestReport(t *testing.T) {
+	s, server := newTestServer(t)
+	s.Report(true, 4)
+	var status Status
+	resp := get(t, server.URL+"/stats")
+	if err := json.NewDecoder(resp.Body).Decode(&status); err != nil {
+		t.Fatal(err)
+	}
+	if !status.Paused || status.Speed != 4 {
+		t.Errorf("status %+v does not show the report", status)
+	}
+}
@@ -8,13 +8,10 @@ import (
 	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
 	"github.com/hajimehoshi/ebiten/v2/inpututil"
 	"image/color"
-	"log"
+	"time"
 )
 
-var (
-	startAnimation *resources.TimedAnimation
-	Quit           = fmt.Errorf("quit game")
-)
+var Quit = fmt.Errorf("quit game")
 
 type GameState int
 
@@ -35,19 +32,29 @@ const (
 type Game struct {
 	screenWidth, screenHeight int
 	GameConfiguration
-	s *simulation.Simulation
+	controls
+	inspector
+	hud
+	s              *simulation.Simulation
+	startAnimation *resources.TimedAnimation
 }
 
 func NewGame(screenWidth, screenHeight int, cnf GameConfiguration) *Game {
-	startAnimation = resources.NewGoTameMeAnimation(float64(screenWidth), float64(screenHeight))
 	return &Game{
 		screenWidth:       screenWidth,
 		screenHeight:      screenHeight,
 		GameConfiguration: cnf,
+		controls:          controls{speedIndex: defaultSpeedIndex},
+		startAnimation:    resources.NewGoTameMeAnimation(float64(screenWidth), float64(screenHeight)),
 		s:                 simulation.NewSimulation(screenWidth, screenHeight, cnf.SimulationConfig),
 	}
 }
 
+// Simulation returns the simulation of the game.
+func (g *Game) Simulation() *simulation.Simulation {
+	return g.s
+}
+
 // region GameState
 
 func (g *Game) togglePause() {
@@ -102,19 +109,26 @@ func (g *Game) Update() error {
 	if err := g.handleKeyEvents(); err != nil {
 		return err
 	}
+	g.handleControls()
+	if err := g.handleRemote(); err != nil {
+		return err
+	}
 	switch g.state {
 	case GameStateStartSimulation:
-		if !startAnimation.Update() {
-			startAnimation.Reset()
+		if !g.startAnimation.Update() {
+			g.startAnimation.Reset()
 			g.state = GameStateRunning
 		}
 		// fallthrough to update the game content while the game is starting
 		fallthrough
 	case GameStateRunning:
 		// run simulation logic when the game is running
-		g.updateRunning()
+		defer g.sample()
+		return g.updateRunning()
 	case GameStatePaused:
-		// placeholder for paused game logic
+		// step the simulation while the game is paused
+		defer g.sample()
+		return g.updateSteps()
 	case GameStateStart:
 		// placeholder for start screen logic
 	case GameStateEnd:
@@ -129,9 +143,10 @@ func (g *Game) Draw(screen *ebiten.Image) {
 	case GameStateRunning:
 		g.drawRunning(screen)
 	case GameStatePaused:
-		// draw the game content when the game is paused
-		ebitenutil.DebugPrint(screen, "Game is paused - press escape to end the game or space to continue")
+		// draw the game content when the game is paused, the message goes above the status line to keep the HUD visible
 		g.drawRunning(screen)
+		ebitenutil.DebugPrintAt(screen, "Game is paused - press escape to end the game, space to continue or right to step",
+			buttonMargin, g.screenHeight-buttonHeight-lineHeight)
 	case GameStateStart:
 		// draw the start screen
 		ebitenutil.DebugPrint(screen, "Start screen - press space to start the game")
@@ -139,23 +154,53 @@ func (g *Game) Draw(screen *ebiten.Image) {
 		// draw the current game content while the game is starting
 		g.drawRunning(screen)
 		// draw the start animation
-		startAnimation.Draw(screen)
+		g.startAnimation.Draw(screen)
 	case GameStateEnd:
 		// draw the end screen
 		ebitenutil.DebugPrint(screen, "End screen - press escape to end the game or space to restart the game")
 	}
 }
 
-func (g *Game) updateRunning() {
-	// run simulation logic when the game is running
-	if err := g.s.Update(); err != nil {
-		log.Fatal(err)
+func (g *Game) updateRunning() error {
+	// run simulation logic when the game is running, as many updates as the tick rate and the speed ask for
+	g.pending += g.speed() * float64(g.tps) / UITPS
+	start := time.Now()
+	for ; g.pending >= 1; g.pending-- {
+		if err := g.s.Update(); err != nil {
+			return err
+		}
+		if time.Since(start) > time.Second/UITPS {
+			// the simulation is too heavy for the requested rate, drop the backlog to keep the interface responsive
+			g.pending = 0
+			break
+		}
+	}
+	return nil
+}
+
+func (g *Game) updateSteps() error {
+	g.pending = 0
+	start := time.Now()
+	for ; g.steps > 0; g.steps-- {
+		if err := g.s.Update(); err != nil {
+			return err
+		}
+		if time.Since(start) > time.Second/UITPS {
+			// keep the interface responsive, the remaining steps run in the next frames
+			g.steps--
+			break
+		}
 	}
+	return nil
 }
 
 func (g *Game) drawRunning(screen *ebiten.Image) {
 	// draw the game content when the game is running
-	g.s.Draw(screen)
+	g.drawWorld(screen)
+	g.drawHUD(screen)
+	g.drawInspector(screen)
+	g.drawLogPanel(screen)
+	g.drawControls(screen)
 }
 
 func (g *Game) Layout(_, _ int) (int, int) {
@@ -21,18 +21,36 @@ THE SOFTWARE.
 */
 package internal
 
-import "github.com/gotameme/core/internal/simulation"
+import (
+	"github.com/gotameme/core/internal/control"
+	"github.com/gotameme/core/internal/simulation"
+)
 
 type GameOption func(*GameConfiguration)
 
+// UITPS is the rate the user interface is updated at, independent of the ticks of the simulation.
+const UITPS = 60
+
 type GameConfiguration struct {
 	simulation.SimulationConfig
 	state GameState
+	// tps is the number of simulation ticks per second at speed 1
+	tps int
+	// remote controls the game from outside
+	remote Remote
+}
+
+// Remote controls a game from outside, e.g. over HTTP.
+type Remote interface {
+	Commands() <-chan control.Command
+	// Report tells the remote whether the game is paused and how fast it runs
+	Report(paused bool, speed float64)
 }
 
 func NewGameConfiguration() *GameConfiguration {
 	return &GameConfiguration{
 		SimulationConfig: *simulation.NewSimulationConfig(),
+		tps:              UITPS,
 	}
 }
 
@@ -41,3 +59,15 @@ func StartImmediately() GameOption {
 		gc.state = GameStateStartSimulation
 	}
 }
+
+func WithTPS(tps int) GameOption {
+	return func(gc *GameConfiguration) {
+		gc.tps = tps
+	}
+}
+
+func WithRemote(remote Remote) GameOption {
+	return func(gc *GameConfiguration) {
+		gc.remote = remote
+	}
+}
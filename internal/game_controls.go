@@ -0,0 +1,209 @@
+/*
+Copyright (c) 2024 Sebastian Kroczek <me@xbug.de>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package internal
+
+import (
+	"fmt"
+	"github.com/gotameme/core/internal/control"
+	"github.com/gotameme/core/internal/simulation"
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+	"image"
+	"image/color"
+	"math"
+)
+
+// speeds are the speed factors of the simulation, faster speeds run several updates per frame
+var speeds = []float64{0.25, 0.5, 1, 2, 4, 16}
+
+const (
+	defaultSpeedIndex = 2
+	// multiStep is the number of ticks stepped by shift+right or the "+10" button
+	multiStep    = 10
+	buttonWidth  = 44
+	buttonHeight = 20
+	buttonMargin = 4
+)
+
+// overlayKeys toggle the debug overlays of simulation.Overlays, in the same order.
+var overlayKeys = []ebiten.Key{ebiten.KeyF1, ebiten.KeyF2, ebiten.KeyF3, ebiten.KeyF4, ebiten.KeyF5, ebiten.KeyF6,
+	ebiten.KeyF7}
+
+// controls hold the speed of the simulation and the ticks still to step while the game is paused.
+type controls struct {
+	speedIndex int
+	// pending counts the updates that are due at the current speed
+	pending float64
+	steps   int
+}
+
+type button struct {
+	label  string
+	rect   image.Rectangle
+	action func(g *Game)
+}
+
+func (g *Game) speed() float64 {
+	return speeds[g.speedIndex]
+}
+
+func (g *Game) faster() {
+	g.speedIndex = min(g.speedIndex+1, len(speeds)-1)
+}
+
+func (g *Game) slower() {
+	g.speedIndex = max(g.speedIndex-1, 0)
+}
+
+// step pauses the game and steps the given number of ticks.
+func (g *Game) step(ticks int) {
+	if g.state == GameStateRunning {
+		g.pauseGame()
+	}
+	if g.state == GameStatePaused {
+		g.steps += ticks
+	}
+}
+
+func (g *Game) buttons() []button {
+	actions := []struct {
+		label  string
+		action func(g *Game)
+	}{
+		{"-", (*Game).slower},
+		{"+", (*Game).faster},
+		{"||", (*Game).togglePause},
+		{">|", func(g *Game) { g.step(1) }},
+		{"+10", func(g *Game) { g.step(multiStep) }},
+	}
+	buttons := make([]button, len(actions))
+	x := g.screenWidth - len(actions)*(buttonWidth+buttonMargin)
+	y := g.screenHeight - buttonHeight - buttonMargin
+	for i, a := range actions {
+		if a.label == "||" && g.state == GameStatePaused {
+			a.label = ">"
+		}
+		buttons[i] = button{
+			label:  a.label,
+			rect:   image.Rect(x, y, x+buttonWidth, y+buttonHeight),
+			action: a.action,
+		}
+		x += buttonWidth + buttonMargin
+	}
+	return buttons
+}
+
+// handleControls handles the keys and buttons that step the simulation and change its speed.
+// Right steps one tick, shift+right steps multiStep ticks, plus and minus change the speed.
+// F1 to F7 toggle the overlays vision, bounds, targets, moves, marks, index and heatmap, F makes the camera follow the
+// selected ant and H toggles the HUD.
+func (g *Game) handleControls() {
+	if g.state != GameStateRunning && g.state != GameStatePaused {
+		return
+	}
+	for i, key := range overlayKeys {
+		if inpututil.IsKeyJustPressed(key) {
+			g.s.ToggleOverlay(simulation.Overlays[i])
+		}
+	}
+	switch {
+	case inpututil.IsKeyJustPressed(ebiten.KeyRight):
+		if ebiten.IsKeyPressed(ebiten.KeyShift) {
+			g.step(multiStep)
+		} else {
+			g.step(1)
+		}
+	case inpututil.IsKeyJustPressed(ebiten.KeyEqual), inpututil.IsKeyJustPressed(ebiten.KeyNumpadAdd):
+		g.faster()
+	case inpututil.IsKeyJustPressed(ebiten.KeyMinus), inpututil.IsKeyJustPressed(ebiten.KeyNumpadSubtract):
+		g.slower()
+	case inpututil.IsKeyJustPressed(ebiten.KeyF):
+		g.toggleFollow()
+	case inpututil.IsKeyJustPressed(ebiten.KeyH):
+		g.toggleHUD()
+	case inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonLeft):
+		cursor := image.Pt(ebiten.CursorPosition())
+		for _, b := range g.buttons() {
+			if cursor.In(b.rect) {
+				b.action(g)
+				return
+			}
+		}
+		g.selectAnt(cursor)
+	}
+}
+
+func (g *Game) drawControls(screen *ebiten.Image) {
+	for _, b := range g.buttons() {
+		vector.DrawFilledRect(screen, float32(b.rect.Min.X), float32(b.rect.Min.Y), buttonWidth, buttonHeight,
+			color.RGBA{A: 0x80}, false)
+		ebitenutil.DebugPrintAt(screen, b.label, b.rect.Min.X+buttonMargin, b.rect.Min.Y+2)
+	}
+	status := fmt.Sprintf("Tick: %d  Speed: %gx", g.s.Tick(), g.speed())
+	if overlay := g.s.Overlay(); overlay != 0 {
+		status += "  Overlays: " + overlay.String()
+	}
+	ebitenutil.DebugPrintAt(screen, status, buttonMargin, g.screenHeight-buttonHeight)
+}
+
+// handleRemote applies the commands of the remote.
+func (g *Game) handleRemote() error {
+	if g.remote == nil {
+		return nil
+	}
+	for {
+		select {
+		case command := <-g.remote.Commands():
+			switch command.Kind {
+			case control.Pause:
+				if g.state == GameStateRunning {
+					g.pauseGame()
+				}
+			case control.Resume:
+				if g.state == GameStatePaused {
+					g.togglePause()
+				}
+			case control.Step:
+				g.step(command.Ticks)
+			case control.SetSpeed:
+				g.setSpeed(command.Speed)
+			case control.Stop:
+				return Quit
+			}
+		default:
+			g.remote.Report(g.state == GameStatePaused, g.speed())
+			return nil
+		}
+	}
+}
+
+// setSpeed picks the speed closest to the given factor.
+func (g *Game) setSpeed(speed float64) {
+	for i, s := range speeds {
+		if math.Abs(math.Log(s/speed)) < math.Abs(math.Log(speeds[g.speedIndex]/speed)) {
+			g.speedIndex = i
+		}
+	}
+}
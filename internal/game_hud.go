@@ -0,0 +1,156 @@
+/*
+Copyright (c) 2024 Sebastian Kroczek <me@xbug.de>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package internal
+
+import (
+	"fmt"
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+	"image/color"
+	"slices"
+	"sort"
+	"time"
+)
+
+const (
+	// chartInterval is the number of ticks between two samples of the chart
+	chartInterval = 10
+	// chartSpan is the number of ticks shown in the chart
+	chartSpan   = 1200
+	chartHeight = 60
+	// maxHUDWidth is the width of the HUD on large screens, smaller screens get a third of their width
+	maxHUDWidth = 280
+)
+
+var chartColor = color.RGBA{R: 0xff, G: 0xd7, B: 0x00, A: 0xff}
+
+// hud holds the sugar over time shown in the chart of the HUD.
+type hud struct {
+	hidden bool
+	// sugar is the delivered sugar of the last chartSpan ticks, the oldest first.
+	// The samples are at least chartInterval ticks apart, more if the game runs fast.
+	sugar []int
+	// ticks are the ticks the samples of sugar were taken at
+	ticks []int
+}
+
+func (g *Game) toggleHUD() {
+	g.hidden = !g.hidden
+}
+
+// sample records the delivered sugar every chartInterval ticks, or less often if the game runs faster than that.
+func (g *Game) sample() {
+	tick := g.s.Tick()
+	if len(g.ticks) > 0 && tick < g.ticks[len(g.ticks)-1]+chartInterval {
+		return
+	}
+	g.sugar = append(g.sugar, g.s.Statistics().SugarDelivered)
+	g.ticks = append(g.ticks, tick)
+	first := 0
+	for g.ticks[first] < tick-chartSpan {
+		first++
+	}
+	g.sugar, g.ticks = g.sugar[first:], g.ticks[first:]
+}
+
+// deliveryRate returns the sugar delivered per 100 ticks over the ticks of the chart.
+func (g *Game) deliveryRate() float64 {
+	last := len(g.sugar) - 1
+	if last < 1 || g.ticks[last] <= g.ticks[0] {
+		return 0
+	}
+	return float64(g.sugar[last]-g.sugar[0]) * 100 / float64(g.ticks[last]-g.ticks[0])
+}
+
+func (g *Game) hudWidth() int {
+	return min(maxHUDWidth, g.screenWidth/3)
+}
+
+// hudHeight returns the height of the HUD, 0 if it is hidden.
+func (g *Game) hudHeight() int {
+	if g.hidden {
+		return 0
+	}
+	return len(g.hudLines())*lineHeight + buttonMargin + chartHeight + buttonMargin
+}
+
+func (g *Game) hudLines() []string {
+	stats := g.s.ColonyStats()
+	elapsed := time.Duration(stats.Tick) * time.Second / time.Duration(g.tps)
+	lines := []string{
+		fmt.Sprintf("Tick: %d (%s)", stats.Tick, elapsed.Truncate(time.Second)),
+		fmt.Sprintf("Sugar: %d (%.1f per 100 ticks)", stats.Sugar, g.deliveryRate()),
+		fmt.Sprintf("Sugar left: %d  In transit: %d", g.s.RemainingSugar(), stats.SugarInTransit),
+		fmt.Sprintf("Ants: %d  Marks: %d", stats.Ants, stats.Marks),
+	}
+	roles := make([]string, 0, len(stats.RolesCount))
+	for role := range stats.RolesCount {
+		roles = append(roles, role)
+	}
+	sort.Strings(roles)
+	for _, role := range roles {
+		name := role
+		if name == "" {
+			name = "default"
+		}
+		lines = append(lines, fmt.Sprintf("  %s: %d", name, stats.RolesCount[role]))
+	}
+	if scores := g.s.Scores(); len(scores) > 1 {
+		sort.SliceStable(scores, func(i, j int) bool {
+			return scores[i].Sugar > scores[j].Sugar
+		})
+		for i, score := range scores {
+			lines = append(lines, fmt.Sprintf("%d. %s: %d", i+1, score.Colony, score.Sugar))
+		}
+	}
+	lines = append(lines, fmt.Sprintf("TPS: %.0f  FPS: %.0f", ebiten.ActualTPS(), ebiten.ActualFPS()))
+	return lines
+}
+
+// drawHUD draws the statistics of the simulation and a chart of the delivered sugar in the top left corner.
+func (g *Game) drawHUD(screen *ebiten.Image) {
+	if g.hidden {
+		return
+	}
+	lines := g.hudLines()
+	width := g.hudWidth()
+	drawPanel(screen, buttonMargin, buttonMargin, width, lines)
+
+	x := float32(buttonMargin)
+	y := float32(buttonMargin + len(lines)*lineHeight + buttonMargin)
+	vector.DrawFilledRect(screen, x, y, float32(width), chartHeight, color.RGBA{A: 0x80}, false)
+	if len(g.sugar) < 2 {
+		return
+	}
+	highest := max(slices.Max(g.sugar), 1)
+	// the chart spans chartSpan ticks, a tick is step pixels wide
+	step := float32(width) / chartSpan
+	point := func(i int) (float32, float32) {
+		return x + float32(g.ticks[i]-g.ticks[0])*step, y + chartHeight - float32(g.sugar[i])*chartHeight/float32(highest)
+	}
+	for i := 1; i < len(g.sugar); i++ {
+		x0, y0 := point(i - 1)
+		x1, y1 := point(i)
+		vector.StrokeLine(screen, x0, y0, x1, y1, 1, chartColor, true)
+	}
+}
@@ -0,0 +1,172 @@
+/*
+Copyright (c) 2024 Sebastian Kroczek <me@xbug.de>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package internal
+
+import (
+	"fmt"
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+	"image"
+	"image/color"
+)
+
+const (
+	// selectRadius is how far from an ant a click may be to select it
+	selectRadius   = 12
+	lineHeight     = 16
+	inspectorWidth = 320
+	// inspectorCallbacks is the number of callbacks shown in the inspector
+	inspectorCallbacks = 8
+	// maxValueLength cuts long field values of the ant code
+	maxValueLength = 40
+	// followZoom is the zoom of the camera while it follows the selected ant
+	followZoom = 2
+)
+
+var trailColor = color.RGBA{R: 0x80, G: 0x80, A: 0x80}
+
+// inspector holds the selected ant and the camera.
+type inspector struct {
+	// selectedAnt is the id of the inspected ant, 0 if none is selected
+	selectedAnt int
+	// follow makes the camera follow the selected ant
+	follow bool
+	// world is the image the simulation is drawn on while the camera follows an ant
+	world *ebiten.Image
+}
+
+// camera returns the transformation of the world to the screen, ok is false if the camera doesn't follow an ant.
+func (g *Game) camera() (camera ebiten.GeoM, ok bool) {
+	if !g.follow {
+		return camera, false
+	}
+	a := g.s.Ant(g.selectedAnt)
+	if a == nil {
+		return camera, false
+	}
+	camera.Translate(-a.Position[0], -a.Position[1])
+	camera.Scale(followZoom, followZoom)
+	camera.Translate(float64(g.screenWidth)/2, float64(g.screenHeight)/2)
+	return camera, true
+}
+
+// toggleFollow makes the camera follow the selected ant or stop following it.
+func (g *Game) toggleFollow() {
+	g.follow = !g.follow
+}
+
+// selectAnt selects the ant at the cursor, a click next to all ants clears the selection.
+func (g *Game) selectAnt(cursor image.Point) {
+	x, y := float64(cursor.X), float64(cursor.Y)
+	if camera, ok := g.camera(); ok {
+		camera.Invert()
+		x, y = camera.Apply(x, y)
+	}
+	g.selectedAnt = 0
+	if a := g.s.AntAt(x, y, selectRadius); a != nil {
+		g.selectedAnt = a.GetId()
+	}
+}
+
+// drawWorld draws the simulation, through the camera if it follows an ant.
+func (g *Game) drawWorld(screen *ebiten.Image) {
+	camera, ok := g.camera()
+	if !ok {
+		g.s.Draw(screen)
+		return
+	}
+	if g.world == nil {
+		g.world = ebiten.NewImage(g.screenWidth, g.screenHeight)
+	}
+	g.world.Clear()
+	g.s.Draw(g.world)
+	screen.DrawImage(g.world, &ebiten.DrawImageOptions{GeoM: camera})
+}
+
+// drawInspector marks the selected ant and draws what it is doing.
+func (g *Game) drawInspector(screen *ebiten.Image) {
+	if g.selectedAnt == 0 {
+		return
+	}
+	// the inspector is drawn below the HUD
+	top := g.hudHeight() + buttonMargin
+	a := g.s.Ant(g.selectedAnt)
+	if a == nil {
+		ebitenutil.DebugPrintAt(screen, fmt.Sprintf("Ant #%d is gone", g.selectedAnt), buttonMargin, top)
+		return
+	}
+	camera, following := g.camera()
+	x, y, radius := a.Position[0], a.Position[1], float64(selectRadius)
+	if following {
+		x, y = camera.Apply(x, y)
+		radius *= followZoom
+	}
+	// the trail shows the way the ant came
+	trail := a.Trail()
+	for i := 1; i < len(trail); i++ {
+		x0, y0 := camera.Apply(trail[i-1][0], trail[i-1][1])
+		x1, y1 := camera.Apply(trail[i][0], trail[i][1])
+		vector.StrokeLine(screen, float32(x0), float32(y0), float32(x1), float32(y1), 1, trailColor, true)
+	}
+	vector.StrokeCircle(screen, float32(x), float32(y), float32(radius), 1, color.RGBA{R: 0xff, G: 0xff, A: 0xff}, true)
+
+	inspection := a.Inspect()
+	state, target := inspection.State, inspection.Target
+	if state == "" {
+		state = "waiting"
+	}
+	if target == "" {
+		target = "none"
+	}
+	lines := []string{
+		fmt.Sprintf("Ant #%d %s (%s)", inspection.ID, inspection.Role, inspection.Colony),
+		fmt.Sprintf("Position: %.0f, %.0f  Direction: %.0f°", inspection.X, inspection.Y, inspection.Direction),
+		fmt.Sprintf("Load: %d/%d  Range: %d/%d", inspection.Load, inspection.Properties.Load, inspection.RemainingRange, inspection.MaxRange),
+		fmt.Sprintf("Speed: %g  Rotation: %d  Vision: %d", inspection.Speed, inspection.Rotation, inspection.Vision),
+		fmt.Sprintf("Energy: %d  Attack: %d", inspection.Energy, inspection.Attack),
+		"State: " + state,
+		"Target: " + target,
+		fmt.Sprintf("Marks seen: %v", inspection.Marks),
+	}
+	for _, field := range inspection.Fields {
+		value := field.Value
+		if len(value) > maxValueLength {
+			value = value[:maxValueLength] + "..."
+		}
+		lines = append(lines, fmt.Sprintf("%s: %s", field.Name, value))
+	}
+	callbacks := inspection.Callbacks
+	if len(callbacks) > inspectorCallbacks {
+		callbacks = callbacks[len(callbacks)-inspectorCallbacks:]
+	}
+	for _, callback := range callbacks {
+		lines = append(lines, fmt.Sprintf("%d %s", callback.Tick, callback.Name))
+	}
+	if g.follow {
+		lines = append(lines, "Following - press F to stop")
+	} else {
+		lines = append(lines, "Press F to follow")
+	}
+	drawPanel(screen, buttonMargin, top, inspectorWidth, lines)
+}
@@ -0,0 +1,65 @@
+/*
+Copyright (c) 2024 Sebastian Kroczek <me@xbug.de>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package internal
+
+import (
+	"fmt"
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+	"image/color"
+)
+
+const (
+	logPanelWidth = 360
+	// logPanelLines is the number of entries shown in the log panel
+	logPanelLines = 12
+)
+
+// drawLogPanel draws the last log entries of the selected ant.
+func (g *Game) drawLogPanel(screen *ebiten.Image) {
+	a := g.s.Ant(g.selectedAnt)
+	if a == nil {
+		return
+	}
+	entries := a.Logs()
+	if len(entries) > logPanelLines {
+		entries = entries[len(entries)-logPanelLines:]
+	}
+	lines := make([]string, 0, len(entries)+1)
+	lines = append(lines, fmt.Sprintf("Log of ant #%d", a.GetId()))
+	for _, entry := range entries {
+		lines = append(lines, entry.String())
+	}
+	drawPanel(screen, g.screenWidth-logPanelWidth-buttonMargin, lineHeight, logPanelWidth, lines)
+}
+
+// drawPanel draws the lines on a translucent background.
+func drawPanel(screen *ebiten.Image, x, y, width int, lines []string) {
+	vector.DrawFilledRect(screen, float32(x), float32(y), float32(width), float32(len(lines)*lineHeight+buttonMargin),
+		color.RGBA{A: 0x80}, false)
+	for _, line := range lines {
+		ebitenutil.DebugPrintAt(screen, line, x+buttonMargin, y)
+		y += lineHeight
+	}
+}
@@ -27,14 +27,25 @@ import (
 	"github.com/hajimehoshi/ebiten/v2/vector"
 	"github.com/paulmach/orb"
 	"image/color"
+	"sync"
 )
 
-var circleCache = make(map[int]map[color.RGBA]*ebiten.Image)
+// CircleCache holds the circle images that were already drawn.
+type CircleCache struct {
+	mu     sync.Mutex
+	images map[int]map[color.RGBA]*ebiten.Image
+}
+
+func NewCircleCache() *CircleCache {
+	return &CircleCache{images: make(map[int]map[color.RGBA]*ebiten.Image)}
+}
 
 // NewCircle returns a new circle image with the given radius and color.
 // The returned image is cached, so the same radius and color won't create a new image.
-func NewCircle(r int, clr color.RGBA) *ebiten.Image {
-	if img, ok := circleCache[r][clr]; ok {
+func (c *CircleCache) NewCircle(r int, clr color.RGBA) *ebiten.Image {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if img, ok := c.images[r][clr]; ok {
 		return img
 	}
 	size := r*2 + 1
@@ -46,10 +57,10 @@ func NewCircle(r int, clr color.RGBA) *ebiten.Image {
 	// vector.StrokeRect(img, 0, 0, float32(2*r), float32(2*r), 3, clr, true)
 	vector.DrawFilledCircle(img, float32(r), float32(r), float32(r), transparentColor, true)
 	vector.StrokeCircle(img, float32(r), float32(r), float32(r)-1.5, 3, transparentColor2, true)
-	if circleCache[r] == nil {
-		circleCache[r] = make(map[color.RGBA]*ebiten.Image)
+	if c.images[r] == nil {
+		c.images[r] = make(map[color.RGBA]*ebiten.Image)
 	}
-	circleCache[r][clr] = img
+	c.images[r][clr] = img
 	return img
 }
 
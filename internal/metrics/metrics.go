@@ -0,0 +1,208 @@
+/*
+Copyright (c) 2024 Sebastian Kroczek <me@xbug.de>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+// Package metrics samples the state of a simulation every few ticks and writes it as CSV or JSON Lines.
+package metrics
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"github.com/gotameme/core/internal/simulation"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type Format int
+
+const (
+	CSV Format = iota
+	JSONLines
+)
+
+// RoleSample holds the numbers of a single role.
+type RoleSample struct {
+	Ants int `json:"ants"`
+	// Delivered is the sugar the ants of the role delivered since the start
+	Delivered int `json:"delivered"`
+}
+
+// Sample is a row of the time series.
+type Sample struct {
+	Tick           int                   `json:"tick"`
+	HillSugar      int                   `json:"hillSugar"`
+	SugarInTransit int                   `json:"sugarInTransit"`
+	Ants           int                   `json:"ants"`
+	IdleAnts       int                   `json:"idleAnts"`
+	Marks          int                   `json:"marks"`
+	Piles          map[int]int           `json:"piles"`
+	Roles          map[string]RoleSample `json:"roles,omitempty"`
+	// TickDuration is the mean duration of the ticks since the last sample
+	TickDuration time.Duration `json:"tickDurationNs"`
+}
+
+// Recorder is a simulation.TickRecorder and a simulation.Observer, it observes the ticks to time them
+// and to count the deliveries. The file is created with the first recorded tick.
+type Recorder struct {
+	path     string
+	interval int
+	format   Format
+
+	file  *os.File
+	buf   *bufio.Writer
+	csv   *csv.Writer
+	roles []string
+
+	tickStart time.Time
+	ticks     int
+	busy      time.Duration
+	delivered map[string]int
+}
+
+func NewRecorder(path string, interval int, format Format) *Recorder {
+	return &Recorder{
+		path:      path,
+		interval:  interval,
+		format:    format,
+		delivered: make(map[string]int),
+	}
+}
+
+func (r *Recorder) Observe(e simulation.Event) {
+	switch e.Kind {
+	case simulation.TickStarted:
+		r.tickStart = time.Now()
+	case simulation.SugarDelivered:
+		r.delivered[e.Role] += e.Amount
+	}
+}
+
+func (r *Recorder) RecordTick(s *simulation.Simulation) error {
+	r.ticks++
+	r.busy += time.Since(r.tickStart)
+	if s.Tick()%r.interval != 0 {
+		return nil
+	}
+	if r.file == nil {
+		if err := r.open(s); err != nil {
+			return err
+		}
+	}
+	sample := r.sample(s)
+	r.ticks, r.busy = 0, 0
+	if r.format == JSONLines {
+		return json.NewEncoder(r.buf).Encode(sample)
+	}
+	return r.writeCSV(sample)
+}
+
+func (r *Recorder) sample(s *simulation.Simulation) Sample {
+	stats := s.ColonyStats()
+	sample := Sample{
+		Tick:           stats.Tick,
+		HillSugar:      stats.Sugar,
+		SugarInTransit: stats.SugarInTransit,
+		Ants:           stats.Ants,
+		IdleAnts:       stats.IdleAnts,
+		Marks:          stats.Marks,
+		Piles:          make(map[int]int),
+		TickDuration:   r.busy / time.Duration(r.ticks),
+	}
+	for _, pile := range s.State().Sugar {
+		sample.Piles[pile.Id] = pile.Amount
+	}
+	if len(r.roles) > 0 {
+		sample.Roles = make(map[string]RoleSample, len(r.roles))
+		for _, role := range r.roles {
+			sample.Roles[role] = RoleSample{Ants: stats.RolesCount[role], Delivered: r.delivered[role]}
+		}
+	}
+	return sample
+}
+
+func (r *Recorder) open(s *simulation.Simulation) error {
+	file, err := os.Create(r.path)
+	if err != nil {
+		return err
+	}
+	r.file = file
+	r.buf = bufio.NewWriter(file)
+	// the columns are fixed by the first sample, so the roles are taken from the configuration
+	r.roles = s.Roles()
+	if r.format != CSV {
+		return nil
+	}
+	r.csv = csv.NewWriter(r.buf)
+	header := []string{"tick", "hill_sugar", "sugar_in_transit", "ants", "idle_ants", "marks", "piles", "tick_duration_ns"}
+	for _, role := range r.roles {
+		header = append(header, role+"_ants", role+"_delivered")
+	}
+	return r.csv.Write(header)
+}
+
+func (r *Recorder) writeCSV(sample Sample) error {
+	// the piles come and go, so they share a column as id:amount pairs
+	ids := make([]int, 0, len(sample.Piles))
+	for id := range sample.Piles {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+	piles := make([]string, len(ids))
+	for i, id := range ids {
+		piles[i] = fmt.Sprintf("%d:%d", id, sample.Piles[id])
+	}
+	row := []string{
+		strconv.Itoa(sample.Tick),
+		strconv.Itoa(sample.HillSugar),
+		strconv.Itoa(sample.SugarInTransit),
+		strconv.Itoa(sample.Ants),
+		strconv.Itoa(sample.IdleAnts),
+		strconv.Itoa(sample.Marks),
+		strings.Join(piles, ";"),
+		strconv.FormatInt(int64(sample.TickDuration), 10),
+	}
+	for _, role := range r.roles {
+		row = append(row, strconv.Itoa(sample.Roles[role].Ants), strconv.Itoa(sample.Roles[role].Delivered))
+	}
+	if err := r.csv.Write(row); err != nil {
+		return err
+	}
+	r.csv.Flush()
+	return r.csv.Error()
+}
+
+// Close flushes and closes the file, it can be called more than once.
+func (r *Recorder) Close() error {
+	if r.file == nil {
+		return nil
+	}
+	err := r.buf.Flush()
+	if closeErr := r.file.Close(); err == nil {
+		err = closeErr
+	}
+	r.file = nil
+	return err
+}
@@ -0,0 +1,140 @@
+/*
+Copyright (c) 2024 Sebastian Kroczek <me@xbug.de>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package render
+
+import (
+	"fmt"
+	"github.com/gotameme/core/internal/simulation"
+	"image"
+	"image/color"
+	"image/color/palette"
+	"image/gif"
+	"image/png"
+	"os"
+)
+
+// Snapshots writes a PNG of the simulation at the given ticks. The file name is the pattern formatted with the tick,
+// like shot-%04d.png.
+type Snapshots struct {
+	pattern string
+	ticks   map[int]bool
+}
+
+func NewSnapshots(pattern string, ticks ...int) *Snapshots {
+	s := &Snapshots{pattern: pattern, ticks: make(map[int]bool, len(ticks))}
+	for _, tick := range ticks {
+		s.ticks[tick] = true
+	}
+	return s
+}
+
+func (s *Snapshots) RecordTick(sim *simulation.Simulation) error {
+	if !s.ticks[sim.Tick()] {
+		return nil
+	}
+	return WritePNG(fmt.Sprintf(s.pattern, sim.Tick()), sim)
+}
+
+// WritePNG writes a PNG of the simulation to path.
+func WritePNG(path string, sim *simulation.Simulation) error {
+	width, height := sim.Layout(0, 0)
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err = png.Encode(file, Render(sim.State(), width, height)); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// GIF records the simulation every interval ticks and writes the frames as animated GIF to path when it is closed.
+// The frames are kept in memory until then, a larger interval keeps long runs small.
+type GIF struct {
+	path     string
+	interval int
+	// delay is the time between two frames in 100ths of a second
+	delay  int
+	frames []*image.Paletted
+	// indices caches the palette index of the colors seen so far, the images have few distinct colors
+	indices map[color.RGBA]uint8
+	closed  bool
+}
+
+// NewGIF records every interval ticks, the animation shows ticksPerSecond ticks per second.
+func NewGIF(path string, interval, ticksPerSecond int) *GIF {
+	return &GIF{
+		path:     path,
+		interval: interval,
+		delay:    max(interval*100/ticksPerSecond, 2),
+		indices:  make(map[color.RGBA]uint8),
+	}
+}
+
+func (g *GIF) RecordTick(sim *simulation.Simulation) error {
+	if sim.Tick()%g.interval != 0 {
+		return nil
+	}
+	width, height := sim.Layout(0, 0)
+	g.frames = append(g.frames, g.paletted(Render(sim.State(), width, height)))
+	return nil
+}
+
+// paletted maps every pixel to the closest color of the Plan 9 palette.
+func (g *GIF) paletted(img *image.RGBA) *image.Paletted {
+	frame := image.NewPaletted(img.Bounds(), palette.Plan9)
+	for y := img.Bounds().Min.Y; y < img.Bounds().Max.Y; y++ {
+		for x := img.Bounds().Min.X; x < img.Bounds().Max.X; x++ {
+			c := img.RGBAAt(x, y)
+			index, ok := g.indices[c]
+			if !ok {
+				index = uint8(frame.Palette.Index(c))
+				g.indices[c] = index
+			}
+			frame.SetColorIndex(x, y, index)
+		}
+	}
+	return frame
+}
+
+// Close writes the recorded frames, it does nothing if no frame was recorded.
+func (g *GIF) Close() error {
+	if g.closed || len(g.frames) == 0 {
+		return nil
+	}
+	g.closed = true
+	animation := &gif.GIF{Image: g.frames, Delay: make([]int, len(g.frames))}
+	for i := range animation.Delay {
+		animation.Delay[i] = g.delay
+	}
+	file, err := os.Create(g.path)
+	if err != nil {
+		return err
+	}
+	if err = gif.EncodeAll(file, animation); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
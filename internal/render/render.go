@@ -0,0 +1,182 @@
+/*
+Copyright (c) 2024 Sebastian Kroczek <me@xbug.de>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+// Package render draws the state of a simulation into an image without Ebiten, so it works without a GPU.
+// It uses the same sprites as the game.
+package render
+
+import (
+	"bytes"
+	"github.com/gotameme/core/internal/resources"
+	"github.com/gotameme/core/internal/simulation"
+	"image"
+	"image/color"
+	"image/draw"
+	_ "image/png"
+	"math"
+	"sync"
+)
+
+const (
+	antFrameWidth    = 12
+	antFrameHeight   = 3
+	antFrames        = 12
+	sugarFrameWidth  = 32
+	sugarFrameHeight = 24
+	sugarFrames      = 11
+	// hillFrameSize is the frame size the game centers the hill with, the image itself is smaller
+	hillFrameSize = 32
+)
+
+var (
+	background = color.RGBA{R: 0x80, G: 0xc0, B: 0xa0, A: 0xff}
+	markColor  = color.RGBA{R: 0x10, G: 0x10, B: 0, A: 0x20}
+	markStroke = color.RGBA{R: 0x30, G: 0x30, B: 0, A: 0x60}
+)
+
+// sprites are the images of the game, decoded once.
+var sprites = sync.OnceValue(func() struct{ ant, sugar, hill image.Image } {
+	return struct{ ant, sugar, hill image.Image }{
+		ant:   decode(resources.Ant3Images_png),
+		sugar: decode(resources.Sugar2_png),
+		hill:  decode(resources.AntHill_png),
+	}
+})
+
+func decode(data []byte) image.Image {
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		panic(err)
+	}
+	return img
+}
+
+// Render draws the state into a new image of the given size.
+func Render(state simulation.State, width, height int) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.Draw(img, img.Bounds(), image.NewUniform(background), image.Point{}, draw.Src)
+	for _, m := range state.Marks {
+		drawMark(img, m)
+	}
+	for _, a := range state.Ants {
+		drawAnt(img, a, state.Tick)
+	}
+	for _, s := range state.Sugar {
+		drawSugar(img, s)
+	}
+	drawHill(img, state.Hill)
+	return img
+}
+
+func drawMark(img *image.RGBA, m simulation.MarkState) {
+	r := float64(m.Radius)
+	fillCircle(img, m.X, m.Y, r, markColor)
+	// the game draws a ring of 3 pixels at the edge
+	strokeCircle(img, m.X, m.Y, r-3, r, markStroke)
+}
+
+// drawSugar picks the frame of the pile like Sugar.Draw does.
+func drawSugar(img *image.RGBA, s simulation.SugarState) {
+	if s.Amount <= 0 {
+		return
+	}
+	animation := min(s.Amount/100+1, 10)
+	if s.Amount < 10 {
+		animation = 1
+	}
+	// the frames are ordered from the fullest to the emptiest pile
+	sx := (sugarFrames - 1 - animation) * sugarFrameWidth
+	frame := image.Rect(sx, 0, sx+sugarFrameWidth, sugarFrameHeight)
+	at := image.Pt(int(s.X)-sugarFrameWidth/2, int(s.Y)-sugarFrameHeight/2)
+	draw.Draw(img, frame.Sub(frame.Min).Add(at), sprites().sugar, frame.Min, draw.Over)
+}
+
+func drawHill(img *image.RGBA, h simulation.HillState) {
+	hill := sprites().hill
+	at := image.Pt(int(h.X)-hillFrameSize/2, int(h.Y)-hillFrameSize/2)
+	draw.Draw(img, hill.Bounds().Sub(hill.Bounds().Min).Add(at), hill, hill.Bounds().Min, draw.Over)
+}
+
+// drawAnt draws the frame of the ant rotated around its center. The animation of the game advances every tick, so the
+// tick picks the frame.
+func drawAnt(img *image.RGBA, a simulation.AntState, tick int) {
+	row := 0
+	if a.Load > 0 {
+		row = antFrameHeight
+	}
+	frame := image.Rect(0, row, antFrameWidth, row+antFrameHeight).Add(image.Pt(tick%antFrames*antFrameWidth, 0))
+	drawRotated(img, sprites().ant, frame, a.X, a.Y, a.Direction*math.Pi/180)
+}
+
+// drawRotated draws the frame of src centered at x, y, rotated by angle. Every pixel of the destination takes the
+// pixel of the frame it comes from.
+func drawRotated(img *image.RGBA, src image.Image, frame image.Rectangle, x, y, angle float64) {
+	w, h := float64(frame.Dx()), float64(frame.Dy())
+	r := math.Ceil(math.Hypot(w, h) / 2)
+	sin, cos := math.Sin(angle), math.Cos(angle)
+	bounds := image.Rect(int(x-r), int(y-r), int(x+r)+1, int(y+r)+1).Intersect(img.Bounds())
+	for py := bounds.Min.Y; py < bounds.Max.Y; py++ {
+		for px := bounds.Min.X; px < bounds.Max.X; px++ {
+			dx, dy := float64(px)+0.5-x, float64(py)+0.5-y
+			// rotate back into the frame
+			sx, sy := dx*cos+dy*sin+w/2, -dx*sin+dy*cos+h/2
+			if sx < 0 || sy < 0 || sx >= w || sy >= h {
+				continue
+			}
+			c := color.RGBAModel.Convert(src.At(frame.Min.X+int(sx), frame.Min.Y+int(sy))).(color.RGBA)
+			if c.A > 0 {
+				blend(img, px, py, c)
+			}
+		}
+	}
+}
+
+func fillCircle(img *image.RGBA, cx, cy, r float64, c color.RGBA) {
+	strokeCircle(img, cx, cy, 0, r, c)
+}
+
+// strokeCircle draws the ring between the inner and the outer radius.
+func strokeCircle(img *image.RGBA, cx, cy, inner, outer float64, c color.RGBA) {
+	bounds := image.Rect(int(cx-outer), int(cy-outer), int(cx+outer)+1, int(cy+outer)+1).Intersect(img.Bounds())
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			px, py := float64(x)+0.5-cx, float64(y)+0.5-cy
+			if d := px*px + py*py; d <= outer*outer && d >= inner*inner {
+				blend(img, x, y, c)
+			}
+		}
+	}
+}
+
+// blend draws the premultiplied color c over the pixel.
+func blend(img *image.RGBA, x, y int, c color.RGBA) {
+	if c.A == 0xff {
+		img.SetRGBA(x, y, c)
+		return
+	}
+	dst := img.RGBAAt(x, y)
+	a := 0xff - uint32(c.A)
+	mix := func(s, d uint8) uint8 {
+		return uint8(uint32(s) + uint32(d)*a/0xff)
+	}
+	img.SetRGBA(x, y, color.RGBA{R: mix(c.R, dst.R), G: mix(c.G, dst.G), B: mix(c.B, dst.B), A: 0xff})
+}
@@ -0,0 +1,118 @@
+/*
+Copyright (c) 2024 Sebastian Kroczek <me@xbug.de>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package replay
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/binary"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"github.com/gotameme/core/internal/simulation"
+	"io"
+	"os"
+)
+
+// keyframeInterval is the number of frames between two full states kept in memory for seeking.
+const keyframeInterval = 100
+
+var ErrNoReplay = errors.New("not a replay file")
+
+// Replay is a replay file loaded into memory.
+type Replay struct {
+	Header
+	frames    []Frame
+	keyframes []simulation.State
+}
+
+func Load(path string) (*Replay, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	buf := bufio.NewReader(file)
+
+	magic := make([]byte, len(Magic))
+	if _, err = io.ReadFull(buf, magic); err != nil || string(magic) != Magic {
+		return nil, ErrNoReplay
+	}
+	var version uint16
+	if err = binary.Read(buf, binary.BigEndian, &version); err != nil {
+		return nil, ErrNoReplay
+	}
+	if version != Version {
+		return nil, fmt.Errorf("unsupported replay version %d, expected %d", version, Version)
+	}
+	gz, err := gzip.NewReader(buf)
+	if err != nil {
+		return nil, err
+	}
+	defer gz.Close()
+	dec := gob.NewDecoder(gz)
+
+	r := &Replay{}
+	if err = dec.Decode(&r.Header); err != nil {
+		return nil, err
+	}
+	var state simulation.State
+	for {
+		var f Frame
+		if err = dec.Decode(&f); errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			// a replay whose recording was interrupted is still playable up to its last complete frame
+			break
+		} else if err != nil {
+			return nil, err
+		}
+		state = Apply(state, f)
+		if len(r.frames)%keyframeInterval == 0 {
+			r.keyframes = append(r.keyframes, state)
+		}
+		r.frames = append(r.frames, f)
+	}
+	if len(r.frames) == 0 {
+		return nil, fmt.Errorf("replay %s has no ticks", path)
+	}
+	return r, nil
+}
+
+// Len returns the number of recorded ticks.
+func (r *Replay) Len() int {
+	return len(r.frames)
+}
+
+// State returns the state after the frame with index i.
+func (r *Replay) State(i int) simulation.State {
+	k := i / keyframeInterval
+	state := r.keyframes[k]
+	for j := k*keyframeInterval + 1; j <= i; j++ {
+		state = Apply(state, r.frames[j])
+	}
+	return state
+}
+
+// Next returns the state after the frame with index i, given the state after the frame before.
+func (r *Replay) Next(state simulation.State, i int) simulation.State {
+	return Apply(state, r.frames[i])
+}
@@ -0,0 +1,103 @@
+/*
+Copyright (c) 2024 Sebastian Kroczek <me@xbug.de>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package replay
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/binary"
+	"encoding/gob"
+	"github.com/gotameme/core/internal/simulation"
+	"os"
+)
+
+// Recorder writes a replay file, it is a simulation.TickRecorder.
+// The file is created with the first recorded tick.
+type Recorder struct {
+	path string
+	file *os.File
+	buf  *bufio.Writer
+	gz   *gzip.Writer
+	enc  *gob.Encoder
+	prev simulation.State
+}
+
+func NewRecorder(path string) *Recorder {
+	return &Recorder{path: path}
+}
+
+func (r *Recorder) RecordTick(s *simulation.Simulation) error {
+	if r.enc == nil {
+		if err := r.open(s); err != nil {
+			return err
+		}
+	}
+	state := s.State()
+	if err := r.enc.Encode(Diff(r.prev, state)); err != nil {
+		return err
+	}
+	r.prev = state
+	return nil
+}
+
+func (r *Recorder) open(s *simulation.Simulation) error {
+	file, err := os.Create(r.path)
+	if err != nil {
+		return err
+	}
+	r.file = file
+	r.buf = bufio.NewWriter(file)
+	if _, err = r.buf.WriteString(Magic); err != nil {
+		return err
+	}
+	if err = binary.Write(r.buf, binary.BigEndian, uint16(Version)); err != nil {
+		return err
+	}
+	r.gz = gzip.NewWriter(r.buf)
+	r.enc = gob.NewEncoder(r.gz)
+	width, height := s.Layout(0, 0)
+	return r.enc.Encode(Header{
+		Seed:         s.Seed(),
+		ScreenWidth:  width,
+		ScreenHeight: height,
+		Colony:       s.ColonyName(),
+		Ants:         s.AntDesiredValue(),
+		Sugar:        s.SugarDesiredValue(),
+	})
+}
+
+// Close flushes and closes the replay file, it can be called more than once.
+func (r *Recorder) Close() error {
+	if r.file == nil {
+		return nil
+	}
+	err := r.gz.Close()
+	if flushErr := r.buf.Flush(); err == nil {
+		err = flushErr
+	}
+	if closeErr := r.file.Close(); err == nil {
+		err = closeErr
+	}
+	r.file = nil
+	return err
+}
@@ -0,0 +1,129 @@
+/*
+Copyright (c) 2024 Sebastian Kroczek <me@xbug.de>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+// Package replay records the visible state of a simulation tick by tick and reads it back.
+//
+// A replay file starts with the magic "GTMR" and a big endian uint16 version, followed by a gzip compressed gob stream.
+// The stream holds one Header and then one Frame per tick, every frame only contains what changed since the tick before.
+package replay
+
+import (
+	"github.com/gotameme/core/internal/simulation"
+)
+
+const (
+	Magic   = "GTMR"
+	Version = 1
+)
+
+// Header describes the simulation a replay was recorded from.
+type Header struct {
+	Seed                      uint64
+	ScreenWidth, ScreenHeight int
+	Colony                    string
+	Ants, Sugar               int
+}
+
+// Frame is the difference between the states of two consecutive ticks.
+type Frame struct {
+	Tick         int
+	Hill         *simulation.HillState
+	Ants         []simulation.AntState
+	RemovedAnts  []int
+	Sugar        []simulation.SugarState
+	RemovedSugar []int
+	Marks        []simulation.MarkState
+	RemovedMarks []int
+}
+
+// Diff returns the frame that turns prev into next.
+func Diff(prev, next simulation.State) Frame {
+	f := Frame{Tick: next.Tick}
+	if prev.Hill != next.Hill {
+		hill := next.Hill
+		f.Hill = &hill
+	}
+	f.Ants, f.RemovedAnts = diff(prev.Ants, next.Ants, func(a simulation.AntState) int { return a.Id })
+	f.Sugar, f.RemovedSugar = diff(prev.Sugar, next.Sugar, func(s simulation.SugarState) int { return s.Id })
+	f.Marks, f.RemovedMarks = diff(prev.Marks, next.Marks, func(m simulation.MarkState) int { return m.Id })
+	return f
+}
+
+// Apply returns the state after the frame, state itself is not modified.
+func Apply(state simulation.State, f Frame) simulation.State {
+	next := simulation.State{Tick: f.Tick, Hill: state.Hill}
+	if f.Hill != nil {
+		next.Hill = *f.Hill
+	}
+	next.Ants = apply(state.Ants, f.Ants, f.RemovedAnts, func(a simulation.AntState) int { return a.Id })
+	next.Sugar = apply(state.Sugar, f.Sugar, f.RemovedSugar, func(s simulation.SugarState) int { return s.Id })
+	next.Marks = apply(state.Marks, f.Marks, f.RemovedMarks, func(m simulation.MarkState) int { return m.Id })
+	return next
+}
+
+func diff[T comparable](prev, next []T, id func(T) int) (changed []T, removed []int) {
+	before := make(map[int]T, len(prev))
+	for _, v := range prev {
+		before[id(v)] = v
+	}
+	for _, v := range next {
+		if old, ok := before[id(v)]; !ok || old != v {
+			changed = append(changed, v)
+		}
+		delete(before, id(v))
+	}
+	for _, v := range prev {
+		if _, ok := before[id(v)]; ok {
+			removed = append(removed, id(v))
+		}
+	}
+	return changed, removed
+}
+
+func apply[T any](prev, changed []T, removed []int, id func(T) int) []T {
+	gone := make(map[int]bool, len(removed))
+	for _, i := range removed {
+		gone[i] = true
+	}
+	updates := make(map[int]T, len(changed))
+	for _, v := range changed {
+		updates[id(v)] = v
+	}
+	next := make([]T, 0, len(prev)+len(changed)-len(removed))
+	for _, v := range prev {
+		if gone[id(v)] {
+			continue
+		}
+		if u, ok := updates[id(v)]; ok {
+			v = u
+			delete(updates, id(v))
+		}
+		next = append(next, v)
+	}
+	// whatever is left over is new, it keeps the order of the frame
+	for _, v := range changed {
+		if _, ok := updates[id(v)]; ok {
+			next = append(next, v)
+		}
+	}
+	return next
+}
@@ -0,0 +1,143 @@
+/*
+Copyright (c) 2024 Sebastian Kroczek <me@xbug.de>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package internal
+
+import (
+	"fmt"
+	"github.com/gotameme/core/internal/replay"
+	"github.com/gotameme/core/internal/simulation"
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+	"image/color"
+)
+
+const (
+	replayMinSpeed  = 0.25
+	replayMaxSpeed  = 16
+	progressBarSize = 6
+)
+
+// ReplayGame plays a replay back with the renderer of the simulation.
+//
+// Space pauses, left and right step one tick, up and down change the speed,
+// home and end jump to the first and last tick and a click on the progress bar seeks.
+type ReplayGame struct {
+	replay *replay.Replay
+	s      *simulation.Simulation
+	state  simulation.State
+	frame  int
+	paused bool
+	speed  float64
+	// pending counts the ticks that are due at the current speed
+	pending float64
+}
+
+func NewReplayGame(r *replay.Replay) *ReplayGame {
+	cnf := simulation.NewSimulationConfig(
+		simulation.WithSeed(r.Seed),
+		simulation.WithColonyName(r.Colony),
+	)
+	g := &ReplayGame{
+		replay: r,
+		s:      simulation.NewSimulation(r.ScreenWidth, r.ScreenHeight, *cnf),
+		speed:  1,
+	}
+	g.seek(0)
+	return g
+}
+
+func (g *ReplayGame) seek(frame int) {
+	frame = max(0, min(frame, g.replay.Len()-1))
+	if frame == g.frame+1 {
+		g.state = g.replay.Next(g.state, frame)
+	} else if frame != g.frame || g.state.Tick == 0 {
+		g.state = g.replay.State(frame)
+	}
+	g.frame = frame
+	g.s.ApplyState(g.state)
+}
+
+func (g *ReplayGame) handleInput() error {
+	switch {
+	case inpututil.IsKeyJustPressed(ebiten.KeyEscape):
+		return Quit
+	case inpututil.IsKeyJustPressed(ebiten.KeySpace):
+		g.paused = !g.paused
+	case inpututil.IsKeyJustPressed(ebiten.KeyRight):
+		g.paused = true
+		g.seek(g.frame + 1)
+	case inpututil.IsKeyJustPressed(ebiten.KeyLeft):
+		g.paused = true
+		g.seek(g.frame - 1)
+	case inpututil.IsKeyJustPressed(ebiten.KeyUp):
+		g.speed = min(g.speed*2, replayMaxSpeed)
+	case inpututil.IsKeyJustPressed(ebiten.KeyDown):
+		g.speed = max(g.speed/2, replayMinSpeed)
+	case inpututil.IsKeyJustPressed(ebiten.KeyHome):
+		g.seek(0)
+	case inpututil.IsKeyJustPressed(ebiten.KeyEnd):
+		g.seek(g.replay.Len() - 1)
+	case inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonLeft):
+		x, y := ebiten.CursorPosition()
+		if y >= g.replay.ScreenHeight-progressBarSize*2 {
+			g.seek(x * g.replay.Len() / g.replay.ScreenWidth)
+		}
+	}
+	return nil
+}
+
+func (g *ReplayGame) Update() error {
+	if err := g.handleInput(); err != nil {
+		return err
+	}
+	if g.paused || g.frame == g.replay.Len()-1 {
+		g.pending = 0
+		return nil
+	}
+	g.pending += g.speed
+	for ; g.pending >= 1 && g.frame < g.replay.Len()-1; g.pending-- {
+		g.seek(g.frame + 1)
+	}
+	return nil
+}
+
+func (g *ReplayGame) Draw(screen *ebiten.Image) {
+	g.s.Draw(screen)
+
+	width, height := float32(g.replay.ScreenWidth), float32(g.replay.ScreenHeight)
+	progress := float32(g.frame+1) / float32(g.replay.Len())
+	vector.DrawFilledRect(screen, 0, height-progressBarSize, width, progressBarSize, color.RGBA{A: 0x80}, false)
+	vector.DrawFilledRect(screen, 0, height-progressBarSize, width*progress, progressBarSize, color.RGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff}, false)
+
+	msg := fmt.Sprintf("Replay of %s - tick %d/%d - speed %gx", g.replay.Colony, g.state.Tick, g.replay.Len(), g.speed)
+	if g.paused {
+		msg += " - paused"
+	}
+	ebitenutil.DebugPrintAt(screen, msg, 0, g.replay.ScreenHeight-progressBarSize-20)
+}
+
+func (g *ReplayGame) Layout(_, _ int) (int, int) {
+	return g.replay.ScreenWidth, g.replay.ScreenHeight
+}
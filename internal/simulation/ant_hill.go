@@ -49,10 +49,10 @@ func NewAntHill(screenWidth, screenHeight int, position orb.Point) *AntHill {
 	}
 }
 
-func NewRandomAntHill(screenWidth, screenHeight, border int) *AntHill {
+func NewRandomAntHill(r *rand.Rand, screenWidth, screenHeight, border int) *AntHill {
 	var minValue = [2]float64{float64(border), float64(border)}
 	var maxValue = [2]float64{float64(screenWidth - border), float64(screenHeight - border)}
-	return NewAntHill(screenWidth, screenHeight, rand.RandomPoint(minValue, maxValue))
+	return NewAntHill(screenWidth, screenHeight, r.RandomPoint(minValue, maxValue))
 }
 
 func (a *AntHill) Draw(screen *ebiten.Image) {
@@ -23,15 +23,13 @@ THE SOFTWARE.
 package simulation
 
 import (
-	"github.com/gotameme/core/internal/helper"
 	"github.com/gotameme/core/internal/resources"
 	"github.com/hajimehoshi/ebiten/v2"
 	"github.com/paulmach/orb"
 	"image/color"
+	"sync/atomic"
 )
 
-var autoAntID int
-
 type AntOptions func(*AntOS)
 
 type AntOSConstructor func(AntOptions) *AntOS
@@ -49,6 +47,12 @@ func WithAntHill(antHill *AntHill) AntOptions {
 	}
 }
 
+func WithColony(colony *Colony) AntOptions {
+	return func(a *AntOS) {
+		a.colony = colony
+	}
+}
+
 func WithRole(role string, properties Properties) AntOptions {
 	return func(a *AntOS) {
 		a.role = role
@@ -60,6 +64,7 @@ type AntOS struct {
 	id         int
 	role       string
 	simulation *Simulation
+	colony     *Colony
 	resources.AnimatedSprite
 	ant interface{}
 
@@ -76,23 +81,38 @@ type AntOS struct {
 
 	SetMarkThreshold int
 	SetMarkResetTime int
+
+	messagesSent int
+	// disabled is set if a callback of the ant panicked
+	disabled atomic.Bool
+	// skipUntil is the last tick whose callbacks are skipped, because the ant exceeded its time budget
+	skipUntil atomic.Int64
+	// logs keeps the last entries the ant logged
+	logs *ring[LogEntry]
+	// callbacks keeps the last callbacks the ant received
+	callbacks *ring[CallbackEntry]
+	// trail keeps the last positions of the ant
+	trail *ring[orb.Point]
 }
 
 func NewAntOS(simulation *Simulation, options ...AntOptions) *AntOS {
-	autoAntID++
+	simulation.lastAntID++
 	antOS := &AntOS{
-		id:               autoAntID,
+		id:               simulation.lastAntID,
 		simulation:       simulation,
 		AnimatedSprite:   resources.NewAnimatedAnt(simulation.screenWidth, simulation.screenHeight),
 		Properties:       simulation.defaultRoleProperties,
 		SetMarkThreshold: 10, // in ticks
+		logs:             newRing[LogEntry](simulation.antLogSize),
+		callbacks:        newRing[CallbackEntry](callbackHistory),
+		trail:            newRing[orb.Point](trailLength),
 	}
 
 	for _, option := range options {
 		option(antOS)
 	}
 
-	antOS.visionImage = helper.NewCircle(int(antOS.Vision), color.RGBA{R: 128, G: 0, B: 0, A: 255})
+	antOS.visionImage = simulation.circles.NewCircle(int(antOS.Vision), color.RGBA{R: 128, G: 0, B: 0, A: 255})
 
 	return antOS
 }
@@ -101,6 +121,11 @@ func (a *AntOS) Init(ant interface{}) {
 	a.ant = ant
 }
 
+// IsDisabled reports whether the ant is disabled because one of its callbacks panicked.
+func (a *AntOS) IsDisabled() bool {
+	return a.disabled.Load()
+}
+
 func (a *AntOS) IsInitialized() bool {
 	return a.ant != nil
 }
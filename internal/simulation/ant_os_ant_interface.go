@@ -37,6 +37,14 @@ func (a *AntOS) GetRole() string {
 	return a.role
 }
 
+func (a *AntOS) Colony() ant.Colony {
+	return antColony{Colony: a.colony, ant: a}
+}
+
+func (a *AntOS) GetOrder() int {
+	return a.colony.Order()
+}
+
 func (a *AntOS) GetCurrentLoad() int {
 	return a.CurrentSugarLoad
 }
@@ -86,7 +94,12 @@ func (a *AntOS) TakeSugar(sugar ant.Sugar) error {
 		return fmt.Errorf("ant is not near the sugar")
 	}
 	// log.Printf("Ant #%d takes sugar\n", a.GetId())
-	a.CurrentSugarLoad += sugar.(*Sugar).GetLoad(int(a.Load) - a.CurrentSugarLoad)
+	taken := sugar.(*Sugar).GetLoad(int(a.Load) - a.CurrentSugarLoad)
+	a.CurrentSugarLoad += taken
+	if taken > 0 {
+		s := sugar.(*Sugar)
+		a.simulation.emit(Event{Kind: SugarTaken, AntID: a.id, SugarID: s.id, Amount: taken, X: s.Position[0], Y: s.Position[1]})
+	}
 	return nil
 }
 
@@ -102,6 +115,12 @@ func (a *AntOS) GotToAntHill() {
 func (a *AntOS) SetMark(radius, information int) {
 	if a.Range >= a.SetMarkResetTime {
 		a.SetMarkResetTime = a.Range + a.SetMarkThreshold
-		a.simulation.AddMarkingAtPosition(a.GetPosition(), radius, information)
+		m := NewMarking(a.simulation, a.GetPosition(), radius, information)
+		m.antID = a.id
+		a.simulation.AddMarking(m)
 	}
 }
+
+func (a *AntOS) Say(msg int) error {
+	return a.simulation.Say(a, msg)
+}
@@ -52,7 +52,7 @@ func (a *AntOS) See() ([2]float64, [2]float64, SearchIter) {
 		case *AntOS:
 			if reflect.TypeOf(a.ant) == reflect.TypeOf(data.(*AntOS).ant) {
 				if seeAnt, ok := a.ant.(interface{ SeeFriend(interface{}) }); ok {
-					seeAnt.SeeFriend(data.(*AntOS).ant)
+					a.call("SeeFriend", func() { seeAnt.SeeFriend(data.(*AntOS).ant) })
 				}
 			}
 		// 	// println("See: ", data.(*AntOS).Name)
@@ -67,7 +67,9 @@ func (a *AntOS) See() ([2]float64, [2]float64, SearchIter) {
 					return true
 				}
 				if distance(a.Position, sugar.Position) <= float64(a.Vision) && a.Target != sugar {
-					sugarAnt.SeeSugar(sugar)
+					a.simulation.emit(Event{Kind: SugarSeen, AntID: a.id, SugarID: sugar.id, Amount: sugar.CurrentSugar,
+						X: sugar.Position[0], Y: sugar.Position[1]})
+					a.call("SeeSugar", func() { sugarAnt.SeeSugar(sugar) })
 				}
 			}
 			// case *Marking:
@@ -101,11 +103,11 @@ func (a *AntOS) Smell() ([2]float64, [2]float64, SearchIter) {
 		// 	// Do nothing
 		case *Marking:
 			if markAnt, ok := a.ant.(interface{ SeeMark(mark ant.Mark) }); ok {
-				if MarkCache.HasMark(a, data.(*Marking)) {
+				if a.simulation.markCache.HasMark(a, data.(*Marking)) {
 					return true
 				}
-				MarkCache.AddMark(a, data.(*Marking))
-				markAnt.SeeMark(data.(*Marking))
+				a.simulation.markCache.AddMark(a, data.(*Marking))
+				a.call("SeeMark", func() { markAnt.SeeMark(data.(*Marking)) })
 				// stop searching if ant saw a marking
 				// return false
 			}
@@ -129,10 +131,16 @@ func (a *AntOS) Collides() ([2]float64, [2]float64, SearchIter) {
 			case *AntHill:
 				// log.Printf("AntOS #%d reached the ant hill", a.GetId())
 				data.(*AntHill).CurrentSugar += a.CurrentSugarLoad
+				if a.CurrentSugarLoad > 0 {
+					a.simulation.recordDelivery()
+					a.simulation.emit(Event{Kind: SugarDelivered, AntID: a.id, Role: a.role, Amount: a.CurrentSugarLoad,
+						X: a.antHill.Position[0], Y: a.antHill.Position[1]})
+					a.colony.addEvent(colonyEvent{kind: sugarDelivered, antID: a.GetId(), amount: a.CurrentSugarLoad})
+				}
 				a.CurrentSugarLoad = 0
 			case *Sugar:
 				if sugarAnt, ok := a.ant.(interface{ ReachedSugar(sugar ant.Sugar) }); ok {
-					sugarAnt.ReachedSugar(data.(*Sugar))
+					a.call("ReachedSugar", func() { sugarAnt.ReachedSugar(data.(*Sugar)) })
 				}
 				// default:
 				// 	log.Println("See something unknown")
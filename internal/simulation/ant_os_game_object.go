@@ -32,12 +32,12 @@ func (a *AntOS) Update() {
 		a.State.Update(a)
 	} else {
 		if waitAnt, ok := a.ant.(interface{ Waits() }); ok {
-			waitAnt.Waits()
+			a.call("Waits", waitAnt.Waits)
 		}
 	}
 	a.AnimatedSprite.Update()
 	if tickAnt, ok := a.ant.(interface{ Tick() }); ok {
-		tickAnt.Tick()
+		a.call("Tick", tickAnt.Tick)
 	}
 }
 
@@ -49,12 +49,6 @@ func (a *AntOS) Draw(screen *ebiten.Image) {
 		a.AnimatedSprite.CurrentAnimation = 0
 	}
 	img := a.AnimatedSprite.Draw()
-	// if a.visionImage != nil {
-	// 	op := &ebiten.DrawImageOptions{}
-	// 	// halfSigthDistance := a.sightDistance / 2
-	// 	op.GeoM.Translate(a.Position.X()-float64(a.Vision), a.Position.Y()-float64(a.Vision))
-	// 	screen.DrawImage(a.visionImage, op)
-	// }
 	op := &ebiten.DrawImageOptions{}
 	op.GeoM.Translate(a.AnimatedSprite.GetCenteredRotationOffset())
 	op.GeoM.Rotate(a.CurrentDirection * gmath.DegToRad)
@@ -48,10 +48,10 @@ func NewApple(screenWidth, screenHeight int, position orb.Point) *Apple {
 	}
 }
 
-func NewRandomApple(screenWidth, screenHeight, border int) *Apple {
+func NewRandomApple(r *rand.Rand, screenWidth, screenHeight, border int) *Apple {
 	var minValue = [2]float64{float64(border), float64(border)}
 	var maxValue = [2]float64{float64(screenWidth - border), float64(screenHeight - border)}
-	return NewApple(screenWidth, screenHeight, rand.RandomPoint(minValue, maxValue))
+	return NewApple(screenWidth, screenHeight, r.RandomPoint(minValue, maxValue))
 }
 
 func (a *Apple) Draw(screen *ebiten.Image) {
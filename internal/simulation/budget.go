@@ -0,0 +1,187 @@
+/*
+Copyright (c) 2024 Sebastian Kroczek <me@xbug.de>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package simulation
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// maxRecordedOffences limits the offences kept in a TimingReport, further offences are only counted.
+const maxRecordedOffences = 100
+
+// BudgetPolicy defines how the simulation handles a colony whose callbacks exceed their time budget.
+type BudgetPolicy int
+
+const (
+	// SkipCallbacks skips the remaining callbacks of the tick, of the offending ant if a callback exceeded its
+	// budget or of the whole colony if the colony exceeded its tick budget.
+	SkipCallbacks BudgetPolicy = iota
+	// PenaliseOffender skips the callbacks of the offender for the rest of the tick and the following penalty ticks.
+	PenaliseOffender
+	// DisqualifyColony stops calling the callbacks of the colony after its first offence.
+	DisqualifyColony
+)
+
+// Offence records a callback or a tick that took longer than its budget.
+type Offence struct {
+	Tick     int
+	AntID    int    // 0 if the colony exceeded its tick budget
+	Callback string // empty if the colony exceeded its tick budget
+	Duration time.Duration
+}
+
+// CallbackTiming sums up the time spent in one kind of callback.
+type CallbackTiming struct {
+	Calls int
+	Total time.Duration
+	Max   time.Duration
+}
+
+// TimingReport sums up the time spent in the callbacks of a colony.
+type TimingReport struct {
+	Colony       string
+	Total        time.Duration
+	MaxTick      time.Duration // the most time spent in the callbacks of a single tick
+	Callbacks    map[string]CallbackTiming
+	Offences     []Offence // the first offences of the colony
+	OffenceCount int
+	Skipped      int // number of callbacks skipped because of offences
+	Disqualified bool
+}
+
+type budget struct {
+	callbackBudget time.Duration
+	tickBudget     time.Duration
+	policy         BudgetPolicy
+	penaltyTicks   int
+
+	tick         atomic.Int64
+	tickTime     atomic.Int64
+	skipUntil    atomic.Int64 // the colony skips all callbacks until this tick, inclusive
+	disqualified atomic.Bool
+
+	mu     sync.Mutex
+	report TimingReport
+}
+
+func newBudget(colony string, cnf SimulationConfig) *budget {
+	return &budget{
+		callbackBudget: cnf.callbackBudget,
+		tickBudget:     cnf.tickBudget,
+		policy:         cnf.budgetPolicy,
+		penaltyTicks:   cnf.penaltyTicks,
+		report: TimingReport{
+			Colony:    colony,
+			Callbacks: make(map[string]CallbackTiming),
+		},
+	}
+}
+
+func (b *budget) startTick(tick int) {
+	b.tick.Store(int64(tick))
+	b.tickTime.Store(0)
+}
+
+func (b *budget) endTick() {
+	tickTime := time.Duration(b.tickTime.Swap(0))
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if tickTime > b.report.MaxTick {
+		b.report.MaxTick = tickTime
+	}
+}
+
+// allows reports whether the callbacks of the ant may be called, antOS is nil for the colony controller.
+func (b *budget) allows(antOS *AntOS) bool {
+	tick := b.tick.Load()
+	if b.disqualified.Load() || b.skipUntil.Load() >= tick || (antOS != nil && antOS.skipUntil.Load() >= tick) {
+		b.mu.Lock()
+		b.report.Skipped++
+		b.mu.Unlock()
+		return false
+	}
+	return true
+}
+
+// account adds the time spent in a callback and applies the budget policy, antOS is nil for the colony controller.
+func (b *budget) account(antOS *AntOS, callback string, elapsed time.Duration) {
+	tick := b.tick.Load()
+	tickTime := time.Duration(b.tickTime.Add(int64(elapsed)))
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.report.Total += elapsed
+	timing := b.report.Callbacks[callback]
+	timing.Calls++
+	timing.Total += elapsed
+	if elapsed > timing.Max {
+		timing.Max = elapsed
+	}
+	b.report.Callbacks[callback] = timing
+
+	if b.callbackBudget > 0 && elapsed > b.callbackBudget {
+		offence := Offence{Tick: int(tick), Callback: callback, Duration: elapsed}
+		if antOS != nil {
+			offence.AntID = antOS.GetId()
+			antOS.skipUntil.Store(tick + b.penalty())
+		}
+		b.offend(offence)
+	}
+	// the tick budget is exceeded only once per tick, by the callback that crosses it
+	if b.tickBudget > 0 && tickTime > b.tickBudget && tickTime-elapsed <= b.tickBudget {
+		b.skipUntil.Store(tick + b.penalty())
+		b.offend(Offence{Tick: int(tick), Duration: tickTime})
+	}
+}
+
+func (b *budget) penalty() int64 {
+	if b.policy == PenaliseOffender {
+		return int64(b.penaltyTicks)
+	}
+	return 0
+}
+
+func (b *budget) offend(offence Offence) {
+	b.report.OffenceCount++
+	if len(b.report.Offences) < maxRecordedOffences {
+		b.report.Offences = append(b.report.Offences, offence)
+	}
+	if b.policy == DisqualifyColony {
+		b.disqualified.Store(true)
+		b.report.Disqualified = true
+	}
+}
+
+func (b *budget) Report() TimingReport {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	report := b.report
+	report.Callbacks = make(map[string]CallbackTiming, len(b.report.Callbacks))
+	for callback, timing := range b.report.Callbacks {
+		report.Callbacks[callback] = timing
+	}
+	report.Offences = append([]Offence(nil), b.report.Offences...)
+	return report
+}
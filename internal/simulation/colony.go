@@ -0,0 +1,174 @@
+/*
+Copyright (c) 2024 Sebastian Kroczek <me@xbug.de>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package simulation
+
+import (
+	"github.com/gotameme/core/ant"
+	"sort"
+	"sync"
+)
+
+type colonyWrite struct {
+	antID   int
+	value   interface{}
+	deleted bool
+}
+
+// Colony holds the state shared by all ants of a colony.
+type Colony struct {
+	name        string
+	mu          sync.RWMutex
+	memory      map[string]interface{}
+	writes      map[string]colonyWrite
+	memoryLimit int
+
+	budget     *budget
+	controller ant.ColonyController
+	order      int
+	spawnRole  string
+	events     []colonyEvent
+}
+
+func NewColony(cnf SimulationConfig) *Colony {
+	return &Colony{
+		name:        cnf.colonyName,
+		memory:      make(map[string]interface{}),
+		writes:      make(map[string]colonyWrite),
+		memoryLimit: cnf.colonyMemoryLimit,
+		budget:      newBudget(cnf.colonyName, cnf),
+		controller:  cnf.colonyController,
+	}
+}
+
+// TimingReport sums up the time spent in the callbacks of the colony so far.
+func (c *Colony) TimingReport() TimingReport {
+	return c.budget.Report()
+}
+
+func (c *Colony) Name() string {
+	return c.name
+}
+
+func (c *Colony) Get(key string) (interface{}, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	value, ok := c.memory[key]
+	return value, ok
+}
+
+func (c *Colony) Keys() []string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	keys := make([]string, 0, len(c.memory))
+	for key := range c.memory {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func (c *Colony) Len() int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return len(c.memory)
+}
+
+func (c *Colony) write(antID int, key string, w colonyWrite) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	// the ant with the highest id wins, as if the ants were updated one after another
+	if pending, ok := c.writes[key]; ok && pending.antID > antID {
+		return nil
+	}
+	if !w.deleted && c.memoryLimit > 0 && c.isNewKey(key) && c.size() >= c.memoryLimit {
+		return ant.ErrColonyMemoryFull
+	}
+	w.antID = antID
+	c.writes[key] = w
+	return nil
+}
+
+// set writes the value to the memory right away, bypassing the pending writes of the tick.
+func (c *Colony) set(key string, value interface{}) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.memory[key]; !ok && c.memoryLimit > 0 && len(c.memory) >= c.memoryLimit {
+		return ant.ErrColonyMemoryFull
+	}
+	c.memory[key] = value
+	return nil
+}
+
+func (c *Colony) delete(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.memory, key)
+}
+
+func (c *Colony) isNewKey(key string) bool {
+	if _, ok := c.memory[key]; ok {
+		return false
+	}
+	pending, ok := c.writes[key]
+	return !ok || pending.deleted
+}
+
+// size returns the number of keys the memory holds once the pending writes are flushed.
+// Pending deletes are not taken into account, they only free memory with the next tick.
+func (c *Colony) size() int {
+	size := len(c.memory)
+	for key, w := range c.writes {
+		if _, ok := c.memory[key]; !ok && !w.deleted {
+			size++
+		}
+	}
+	return size
+}
+
+// Flush applies all writes of the current tick to the memory.
+func (c *Colony) Flush() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for key, w := range c.writes {
+		if w.deleted {
+			delete(c.memory, key)
+		} else {
+			c.memory[key] = w.value
+		}
+	}
+	clear(c.writes)
+}
+
+// antColony is the view of an ant on its colony, writes are tagged with the id of the ant.
+type antColony struct {
+	*Colony
+	ant *AntOS
+}
+
+func (a antColony) Set(key string, value interface{}) error {
+	return a.write(a.ant.GetId(), key, colonyWrite{value: value})
+}
+
+func (a antColony) Delete(key string) {
+	_ = a.write(a.ant.GetId(), key, colonyWrite{deleted: true})
+}
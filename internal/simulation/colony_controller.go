@@ -0,0 +1,131 @@
+/*
+Copyright (c) 2024 Sebastian Kroczek <me@xbug.de>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package simulation
+
+import (
+	"github.com/gotameme/core/ant"
+	"sort"
+)
+
+type colonyEventKind int
+
+const (
+	antSpawned colonyEventKind = iota
+	antDied
+	sugarDelivered
+)
+
+type colonyEvent struct {
+	kind   colonyEventKind
+	antID  int
+	role   string
+	amount int
+}
+
+func (c *Colony) addEvent(e colonyEvent) {
+	if c.controller == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.events = append(c.events, e)
+}
+
+func (c *Colony) Order() int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.order
+}
+
+func (c *Colony) SpawnRole() string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.spawnRole
+}
+
+// TickController delivers the events of the last tick to the controller and ticks it afterward.
+func (c *Colony) TickController(stats ant.ColonyStats) {
+	if c.controller == nil {
+		return
+	}
+	c.mu.Lock()
+	events := c.events
+	c.events = nil
+	c.mu.Unlock()
+
+	// events are collected concurrently, so sort them to deliver them in a deterministic order
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].antID < events[j].antID
+	})
+	for _, e := range events {
+		switch e.kind {
+		case antSpawned:
+			if controller, ok := c.controller.(interface{ AntSpawned(int, string) }); ok {
+				controller.AntSpawned(e.antID, e.role)
+			}
+		case antDied:
+			if controller, ok := c.controller.(interface{ AntDied(int) }); ok {
+				controller.AntDied(e.antID)
+			}
+		case sugarDelivered:
+			if controller, ok := c.controller.(interface{ SugarDelivered(int, int) }); ok {
+				controller.SugarDelivered(e.antID, e.amount)
+			}
+		}
+	}
+	c.controller.Tick(colonyOs{c}, stats)
+}
+
+// colonyOs is the view of a ColonyController on its colony.
+type colonyOs struct {
+	colony *Colony
+}
+
+func (o colonyOs) Colony() ant.Colony {
+	return controllerColony{o.colony}
+}
+
+func (o colonyOs) SetOrder(order int) {
+	o.colony.mu.Lock()
+	defer o.colony.mu.Unlock()
+	o.colony.order = order
+}
+
+func (o colonyOs) SetSpawnRole(role string) {
+	o.colony.mu.Lock()
+	defer o.colony.mu.Unlock()
+	o.colony.spawnRole = role
+}
+
+// controllerColony writes directly to the memory of the colony, see ant.ColonyOs.
+type controllerColony struct {
+	*Colony
+}
+
+func (c controllerColony) Set(key string, value interface{}) error {
+	return c.set(key, value)
+}
+
+func (c controllerColony) Delete(key string) {
+	c.delete(key)
+}
@@ -0,0 +1,150 @@
+/*
+Copyright (c) 2024 Sebastian Kroczek <me@xbug.de>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package simulation
+
+import (
+	"fmt"
+	"runtime/debug"
+	"time"
+)
+
+// FaultPolicy defines how the simulation handles an ant whose callback panicked.
+type FaultPolicy int
+
+const (
+	// AbortSimulation ends the simulation with the fault as error.
+	AbortSimulation FaultPolicy = iota
+	// DisableAnt keeps the ant in the simulation, but it stops and none of its callbacks is called anymore.
+	DisableAnt
+	// RespawnAnt removes the ant from the simulation, a new ant is spawned in its place with the next tick.
+	RespawnAnt
+)
+
+// Fault records a panic inside a callback of an ant or a colony controller.
+type Fault struct {
+	Tick     int
+	AntID    int // 0 if the colony controller panicked
+	Colony   string
+	Callback string
+	Panic    string
+	Stack    string
+}
+
+func (f *Fault) Error() string {
+	if f.AntID == 0 {
+		return fmt.Sprintf("colony controller of %s panicked in %s at tick %d: %s", f.Colony, f.Callback, f.Tick, f.Panic)
+	}
+	return fmt.Sprintf("ant #%d of colony %s panicked in %s at tick %d: %s", f.AntID, f.Colony, f.Callback, f.Tick, f.Panic)
+}
+
+// call runs a callback of the user ant, it accounts the time spent and turns a panic into a fault of the ant.
+func (a *AntOS) call(callback string, fn func()) {
+	if a.disabled.Load() || !a.colony.budget.allows(a) {
+		return
+	}
+	a.callbacks.add(CallbackEntry{Tick: a.simulation.tick, Name: callback})
+	start := time.Now()
+	defer func() {
+		a.colony.budget.account(a, callback, time.Since(start))
+		if r := recover(); r != nil {
+			a.disabled.Store(true)
+			a.simulation.addFault(&Fault{
+				Tick:     a.simulation.tick,
+				AntID:    a.GetId(),
+				Colony:   a.colony.name,
+				Callback: callback,
+				Panic:    fmt.Sprint(r),
+				Stack:    string(debug.Stack()),
+			}, a)
+		}
+	}()
+	fn()
+}
+
+func (s *Simulation) addFault(fault *Fault, antOS *AntOS) {
+	s.logger.Error("callback panicked", "tick", fault.Tick, "ant", fault.AntID, "colony", fault.Colony,
+		"callback", fault.Callback, "panic", fault.Panic)
+	s.queueMutex.Lock()
+	defer s.queueMutex.Unlock()
+	s.faults = append(s.faults, fault)
+	if antOS != nil {
+		s.faultyAnts = append(s.faultyAnts, antOS)
+	}
+}
+
+func (s *Simulation) tickColonyController() {
+	if s.colony.controller == nil || !s.colony.budget.allows(nil) {
+		return
+	}
+	start := time.Now()
+	defer func() {
+		s.colony.budget.account(nil, "ColonyController", time.Since(start))
+		if r := recover(); r != nil {
+			// a panicking controller is never called again, unless the simulation is aborted anyway
+			s.colony.controller = nil
+			s.addFault(&Fault{
+				Tick:     s.tick,
+				Colony:   s.colony.name,
+				Callback: "ColonyController",
+				Panic:    fmt.Sprint(r),
+				Stack:    string(debug.Stack()),
+			}, nil)
+		}
+	}()
+	s.colony.TickController(s.ColonyStats())
+}
+
+// Faults returns all faults recorded so far.
+func (s *Simulation) Faults() []*Fault {
+	s.queueMutex.Lock()
+	defer s.queueMutex.Unlock()
+	return append([]*Fault(nil), s.faults...)
+}
+
+// handleFaults applies the fault policy to the ants that panicked during the current tick.
+// It returns the first fault of the tick if the simulation has to be aborted.
+func (s *Simulation) handleFaults(faultsBefore int) error {
+	s.queueMutex.Lock()
+	faults := s.faults[faultsBefore:]
+	faultyAnts := s.faultyAnts
+	s.faultyAnts = nil
+	s.queueMutex.Unlock()
+
+	if len(faults) == 0 {
+		return nil
+	}
+	if s.faultPolicy == AbortSimulation {
+		s.abort = faults[0]
+		return s.abort
+	}
+	for _, antOS := range faultyAnts {
+		switch s.faultPolicy {
+		case DisableAnt:
+			antOS.State = nil
+			antOS.Target = nil
+		case RespawnAnt:
+			s.RemoveAnt(antOS)
+		}
+	}
+	return nil
+}
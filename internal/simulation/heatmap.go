@@ -0,0 +1,197 @@
+/*
+Copyright (c) 2024 Sebastian Kroczek <me@xbug.de>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package simulation
+
+import (
+	"github.com/paulmach/orb"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"sort"
+)
+
+const (
+	defaultHeatmapCellSize = 16
+	// trailLength is the number of positions remembered per ant
+	trailLength = 256
+	// heatmapAlpha is the opacity of the hottest cell
+	heatmapAlpha = 0xc0
+)
+
+// HeatmapSplit defines the layers a heatmap keeps apart.
+type HeatmapSplit int
+
+const (
+	// HeatmapTotal keeps a single layer for all ants
+	HeatmapTotal HeatmapSplit = iota
+	// HeatmapByRole keeps a layer per role
+	HeatmapByRole
+	// HeatmapByLoad keeps a layer for loaded and one for empty ants
+	HeatmapByLoad
+)
+
+// LayerLoaded and LayerEmpty are the layers of HeatmapByLoad.
+const (
+	LayerLoaded = "loaded"
+	LayerEmpty  = "empty"
+)
+
+// Heatmap counts how often an ant was in each cell of a coarse grid over the world.
+type Heatmap struct {
+	cellSize   int
+	cols, rows int
+	split      HeatmapSplit
+	layers     map[string][]int
+}
+
+func newHeatmap(screenWidth, screenHeight, cellSize int, split HeatmapSplit) *Heatmap {
+	return &Heatmap{
+		cellSize: cellSize,
+		cols:     (screenWidth + cellSize - 1) / cellSize,
+		rows:     (screenHeight + cellSize - 1) / cellSize,
+		split:    split,
+		layers:   make(map[string][]int),
+	}
+}
+
+// CellSize returns the width and height of a cell in pixels.
+func (h *Heatmap) CellSize() int {
+	return h.cellSize
+}
+
+// record counts the cell the ant is in.
+func (h *Heatmap) record(a *AntOS) {
+	col, row := int(a.Position[0])/h.cellSize, int(a.Position[1])/h.cellSize
+	if col < 0 || row < 0 || col >= h.cols || row >= h.rows {
+		return
+	}
+	var layer string
+	switch h.split {
+	case HeatmapByRole:
+		layer = a.role
+	case HeatmapByLoad:
+		layer = LayerEmpty
+		if a.CurrentSugarLoad > 0 {
+			layer = LayerLoaded
+		}
+	}
+	if h.layers[layer] == nil {
+		h.layers[layer] = make([]int, h.cols*h.rows)
+	}
+	h.layers[layer][row*h.cols+col]++
+}
+
+// Layers returns the names of the layers, sorted. The layer of HeatmapTotal and of the default role is "".
+func (h *Heatmap) Layers() []string {
+	layers := make([]string, 0, len(h.layers))
+	for layer := range h.layers {
+		layers = append(layers, layer)
+	}
+	sort.Strings(layers)
+	return layers
+}
+
+// counts returns the counts of the layer, or the sum of all layers if layer is nil.
+func (h *Heatmap) counts(layer *string) []int {
+	if layer != nil {
+		return h.layers[*layer]
+	}
+	sum := make([]int, h.cols*h.rows)
+	for _, counts := range h.layers {
+		for i, count := range counts {
+			sum[i] += count
+		}
+	}
+	return sum
+}
+
+// Image returns the heatmap of all layers with a pixel per cell, from transparent blue for rare cells to opaque red
+// for the most visited cell.
+func (h *Heatmap) Image() *image.RGBA {
+	return h.image(h.counts(nil))
+}
+
+// LayerImage returns the heatmap of a single layer like Image.
+func (h *Heatmap) LayerImage(layer string) *image.RGBA {
+	return h.image(h.counts(&layer))
+}
+
+func (h *Heatmap) image(counts []int) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, h.cols, h.rows))
+	highest := 0
+	for _, count := range counts {
+		highest = max(highest, count)
+	}
+	if highest == 0 {
+		return img
+	}
+	for i, count := range counts {
+		if count > 0 {
+			img.SetRGBA(i%h.cols, i/h.cols, heatColor(float64(count)/float64(highest)))
+		}
+	}
+	return img
+}
+
+// heatColor fades from blue over green to red, the alpha is premultiplied.
+func heatColor(heat float64) color.RGBA {
+	var r, g, b float64
+	if heat < 0.5 {
+		g, b = heat*2, 1-heat*2
+	} else {
+		r, g = heat*2-1, 2-heat*2
+	}
+	a := heatmapAlpha * (0.25 + 0.75*heat)
+	return color.RGBA{R: uint8(r * a), G: uint8(g * a), B: uint8(b * a), A: uint8(a)}
+}
+
+// EncodePNG writes the image scaled to the size of the world as PNG.
+func EncodePNG(w io.Writer, img *image.RGBA, cellSize int) error {
+	bounds := img.Bounds()
+	scaled := image.NewRGBA(image.Rect(0, 0, bounds.Dx()*cellSize, bounds.Dy()*cellSize))
+	for y := scaled.Bounds().Min.Y; y < scaled.Bounds().Max.Y; y++ {
+		for x := scaled.Bounds().Min.X; x < scaled.Bounds().Max.X; x++ {
+			scaled.SetRGBA(x, y, img.RGBAAt(x/cellSize, y/cellSize))
+		}
+	}
+	return png.Encode(w, scaled)
+}
+
+// Heatmap returns the occupancy of the world by the ants since the start of the simulation.
+func (s *Simulation) Heatmap() *Heatmap {
+	return s.heatmap
+}
+
+// Trail returns the last positions of the ant, the oldest first.
+func (a *AntOS) Trail() []orb.Point {
+	return a.trail.all()
+}
+
+// recordPositions adds the positions of the ants to the heatmap and their trails.
+func (s *Simulation) recordPositions() {
+	for _, a := range s.ants {
+		s.heatmap.record(a)
+		a.trail.add(a.Position)
+	}
+}
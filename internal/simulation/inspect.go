@@ -0,0 +1,135 @@
+/*
+Copyright (c) 2024 Sebastian Kroczek <me@xbug.de>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package simulation
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+// callbackHistory is the number of callbacks remembered per ant.
+const callbackHistory = 16
+
+// CallbackEntry is a callback an ant received.
+type CallbackEntry struct {
+	Tick int
+	Name string
+}
+
+// Field is an exported field of the ant code.
+type Field struct {
+	Name  string
+	Value string
+}
+
+// Inspection is a snapshot of an ant for the inspector.
+type Inspection struct {
+	ID     int
+	Role   string
+	Colony string
+	Properties
+	X, Y      float64
+	Direction float64
+	Load      int
+	// MaxRange is the range of the role of the ant
+	MaxRange int
+	// RemainingRange is the range the ant has left, Properties.Range counts up every tick
+	RemainingRange int
+	// State describes what the ant is doing, empty if it waits
+	State string
+	// Target describes where the ant is heading, empty if it has no target
+	Target string
+	// Marks are the ids of the marks the ant smelled that still exist
+	Marks []int
+	// Fields are the exported fields of the ant code
+	Fields []Field
+	// Callbacks are the last callbacks the ant received, the oldest first
+	Callbacks []CallbackEntry
+}
+
+// Inspect returns a snapshot of the ant.
+func (a *AntOS) Inspect() Inspection {
+	inspection := Inspection{
+		ID:         a.id,
+		Role:       a.role,
+		Colony:     a.colony.name,
+		Properties: a.Properties,
+		X:          a.Position[0],
+		Y:          a.Position[1],
+		Direction:  a.CurrentDirection,
+		Load:       a.CurrentSugarLoad,
+		Fields:     exportedFields(a.ant),
+		Callbacks:  a.callbacks.all(),
+	}
+	inspection.MaxRange = a.simulation.defaultRoleProperties.Range
+	if properties, ok := a.simulation.roles[a.role]; ok {
+		inspection.MaxRange = properties.Range
+	}
+	// Range starts at the range of the role and counts up every tick
+	used := a.Range - inspection.MaxRange
+	inspection.RemainingRange = max(inspection.MaxRange-used, 0)
+	if moving, ok := a.State.(*AntOSMoving); ok {
+		inspection.State = "moving"
+		if moving.TargetDirection != nil {
+			inspection.State += fmt.Sprintf(", turning to %.0f°", *moving.TargetDirection)
+		}
+		if moving.Steps != nil {
+			inspection.State += fmt.Sprintf(", %d steps left", *moving.Steps)
+		}
+	}
+	switch target := a.Target.(type) {
+	case *Sugar:
+		inspection.Target = fmt.Sprintf("sugar #%d (%d left)", target.id, target.CurrentSugar)
+	case *AntHill:
+		inspection.Target = "ant hill"
+	}
+	for _, m := range a.simulation.markCache.Marks(a) {
+		inspection.Marks = append(inspection.Marks, m.id)
+	}
+	sort.Ints(inspection.Marks)
+	return inspection
+}
+
+// exportedFields returns the exported fields of the struct v or v points to.
+func exportedFields(v interface{}) []Field {
+	value := reflect.ValueOf(v)
+	for value.Kind() == reflect.Pointer || value.Kind() == reflect.Interface {
+		if value.IsNil() {
+			return nil
+		}
+		value = value.Elem()
+	}
+	if value.Kind() != reflect.Struct {
+		return nil
+	}
+	var fields []Field
+	for i := 0; i < value.NumField(); i++ {
+		field := value.Type().Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		fields = append(fields, Field{Name: field.Name, Value: fmt.Sprintf("%+v", value.Field(i).Interface())})
+	}
+	return fields
+}
@@ -0,0 +1,119 @@
+/*
+Copyright (c) 2024 Sebastian Kroczek <me@xbug.de>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package simulation
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+)
+
+// defaultAntLogSize is the number of log entries kept per ant.
+const defaultAntLogSize = 32
+
+// LogEntry is an entry an ant logged with AntOs.Log.
+type LogEntry struct {
+	Tick    int
+	Level   slog.Level
+	Message string
+	// Attrs are the attributes the ant added, formatted as key=value
+	Attrs string
+}
+
+func (e LogEntry) String() string {
+	if e.Attrs == "" {
+		return fmt.Sprintf("%d %s", e.Tick, e.Message)
+	}
+	return fmt.Sprintf("%d %s %s", e.Tick, e.Message, e.Attrs)
+}
+
+// levelHandler drops the records below its level before they reach the handler.
+type levelHandler struct {
+	level slog.Leveler
+	slog.Handler
+}
+
+func (h levelHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return level >= h.level.Level() && h.Handler.Enabled(ctx, level)
+}
+
+func (h levelHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return levelHandler{h.level, h.Handler.WithAttrs(attrs)}
+}
+
+func (h levelHandler) WithGroup(name string) slog.Handler {
+	return levelHandler{h.level, h.Handler.WithGroup(name)}
+}
+
+func newLogger(handler slog.Handler, level slog.Leveler) *slog.Logger {
+	if handler == nil {
+		handler = slog.Default().Handler()
+	}
+	if level == nil {
+		level = slog.LevelInfo
+	}
+	return slog.New(levelHandler{level, handler})
+}
+
+// Logger returns the logger of the engine.
+func (s *Simulation) Logger() *slog.Logger {
+	return s.logger
+}
+
+// Log logs a message of the ant, tagged with the tick, the ant, its role and its colony.
+// The entry is also kept in the log of the ant, see Logs.
+func (a *AntOS) Log(msg string, args ...any) {
+	a.simulation.antLogger.Info(msg, append([]any{
+		"tick", a.simulation.tick,
+		"ant", a.id,
+		"role", a.role,
+		"colony", a.colony.name,
+	}, args...)...)
+	a.logs.add(LogEntry{
+		Tick:    a.simulation.tick,
+		Level:   slog.LevelInfo,
+		Message: msg,
+		Attrs:   formatAttrs(args),
+	})
+}
+
+// Logs returns the last entries the ant logged, the oldest first.
+func (a *AntOS) Logs() []LogEntry {
+	return a.logs.all()
+}
+
+func formatAttrs(args []any) string {
+	if len(args) == 0 {
+		return ""
+	}
+	// a record formats the arguments the same way slog does, including a missing key
+	var attrs []string
+	record := slog.Record{}
+	record.Add(args...)
+	record.Attrs(func(attr slog.Attr) bool {
+		attrs = append(attrs, attr.String())
+		return true
+	})
+	return strings.Join(attrs, " ")
+}
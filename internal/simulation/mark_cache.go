@@ -23,44 +23,59 @@ package simulation
 
 import "sync"
 
-type markCache map[*AntOS]map[*Marking]struct{}
+// markCache remembers which marks an ant already smelled, so it smells every mark only once.
+type markCache struct {
+	mu   sync.RWMutex
+	seen map[*AntOS]map[*Marking]struct{}
+}
 
-var markCacheSyncMutex = sync.RWMutex{}
+func newMarkCache() *markCache {
+	return &markCache{seen: make(map[*AntOS]map[*Marking]struct{})}
+}
 
 func (m *markCache) AddMark(pa *AntOS, pm *Marking) {
 	// Lock the mutex to prevent concurrent map writes
-	markCacheSyncMutex.Lock()
-	defer markCacheSyncMutex.Unlock()
-	if _, ok := (*m)[pa]; !ok {
-		(*m)[pa] = make(map[*Marking]struct{})
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.seen[pa]; !ok {
+		m.seen[pa] = make(map[*Marking]struct{})
 	}
-	(*m)[pa][pm] = struct{}{}
+	m.seen[pa][pm] = struct{}{}
 }
 
 func (m *markCache) RemoveMark(pm *Marking) {
 	// Lock the mutex to prevent concurrent map writes
-	markCacheSyncMutex.Lock()
-	defer markCacheSyncMutex.Unlock()
-	for _, marks := range *m {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, marks := range m.seen {
 		delete(marks, pm)
 	}
 }
 
 func (m *markCache) RemoveAnt(pa *AntOS) {
 	// Lock the mutex to prevent concurrent map writes
-	markCacheSyncMutex.Lock()
-	defer markCacheSyncMutex.Unlock()
-	delete(*m, pa)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.seen, pa)
+}
+
+// Marks returns the marks the ant smelled.
+func (m *markCache) Marks(pa *AntOS) []*Marking {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	marks := make([]*Marking, 0, len(m.seen[pa]))
+	for pm := range m.seen[pa] {
+		marks = append(marks, pm)
+	}
+	return marks
 }
 
 func (m *markCache) HasMark(pa *AntOS, pm *Marking) (ok bool) {
 	// Lock the mutex to prevent concurrent map reads
-	markCacheSyncMutex.RLock()
-	defer markCacheSyncMutex.RUnlock()
-	if _, ok = (*m)[pa]; ok {
-		_, ok = (*m)[pa][pm]
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if _, ok = m.seen[pa]; ok {
+		_, ok = m.seen[pa][pm]
 	}
 	return
 }
-
-var MarkCache = markCache{}
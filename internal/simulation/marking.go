@@ -22,13 +22,14 @@ THE SOFTWARE.
 package simulation
 
 import (
-	"github.com/gotameme/core/internal/helper"
 	"github.com/hajimehoshi/ebiten/v2"
 	"github.com/paulmach/orb"
 	"image/color"
 )
 
 type Marking struct {
+	id          int
+	antID       int // the ant that set the mark
 	simulation  *Simulation
 	img         *ebiten.Image
 	op          *ebiten.DrawImageOptions
@@ -50,7 +51,7 @@ func NewMarking(simulation *Simulation, position orb.Point, radius int, informat
 	// 	Information: information,
 	// 	Range:    150, // ticks
 	// }
-	m := GetMarking()
+	m := simulation.markingPool.Get()
 	m.simulation = simulation
 	m.Position = position
 	m.Radius = radius
@@ -61,7 +62,7 @@ func NewMarking(simulation *Simulation, position orb.Point, radius int, informat
 
 func (m *Marking) Draw(screen *ebiten.Image) {
 	if m.img == nil {
-		circle := helper.NewCircle(m.Radius, color.RGBA{R: 128, G: 128, B: 0, A: 255})
+		circle := m.simulation.circles.NewCircle(m.Radius, color.RGBA{R: 128, G: 128, B: 0, A: 255})
 		op := &ebiten.DrawImageOptions{}
 		op.GeoM.Translate(m.Position[0]-float64(m.Radius), m.Position[1]-float64(m.Radius))
 		m.img = circle
@@ -26,23 +26,34 @@ import (
 	"sync"
 )
 
-var markingPool = sync.Pool{
-	New: func() interface{} {
-		return &Marking{
-			simulation:  nil,
-			Position:    orb.Point{},
-			Radius:      0,
-			Information: 0,
-			Lifespan:    150,
-		}
-	},
+// markingPool recycles the markings of a simulation, markings come and go all the time.
+type markingPool struct {
+	pool sync.Pool
 }
 
-func GetMarking() *Marking {
-	return markingPool.Get().(*Marking)
+func newMarkingPool() *markingPool {
+	return &markingPool{
+		pool: sync.Pool{
+			New: func() interface{} {
+				return &Marking{
+					simulation:  nil,
+					Position:    orb.Point{},
+					Radius:      0,
+					Information: 0,
+					Lifespan:    150,
+				}
+			},
+		},
+	}
 }
 
-func PutMarking(m *Marking) {
+func (p *markingPool) Get() *Marking {
+	return p.pool.Get().(*Marking)
+}
+
+func (p *markingPool) Put(m *Marking) {
+	m.id = 0
+	m.antID = 0
 	m.img = nil
-	markingPool.Put(m)
+	p.pool.Put(m)
 }
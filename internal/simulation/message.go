@@ -0,0 +1,98 @@
+/*
+Copyright (c) 2024 Sebastian Kroczek <me@xbug.de>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package simulation
+
+import (
+	"github.com/gotameme/core/ant"
+	"sort"
+
+	gmath "github.com/gotameme/core/internal/math"
+)
+
+type message struct {
+	from *AntOS
+	msg  int
+}
+
+// Say queues a message of the ant, it is delivered to all friends within the hearing radius at the end of the tick.
+func (s *Simulation) Say(from *AntOS, msg int) error {
+	s.queueMutex.Lock()
+	defer s.queueMutex.Unlock()
+	if from.messagesSent >= s.messageBandwidth {
+		return ant.ErrBandwidthExceeded
+	}
+	from.messagesSent++
+	s.messageQueue = append(s.messageQueue, message{from: from, msg: msg})
+	return nil
+}
+
+// FlushMessages delivers the queued messages ordered by the id of the sender.
+// Messages said while hearing are delivered with the next tick.
+func (s *Simulation) FlushMessages() {
+	s.queueMutex.Lock()
+	messages := s.messageQueue
+	s.messageQueue = nil
+	for _, m := range messages {
+		m.from.messagesSent = 0
+	}
+	s.queueMutex.Unlock()
+
+	sort.SliceStable(messages, func(i, j int) bool {
+		return messages[i].from.GetId() < messages[j].from.GetId()
+	})
+	for _, m := range messages {
+		for _, receiver := range s.hearingAnts(m.from) {
+			if hearAnt, ok := receiver.ant.(interface{ Hear(ant.FriendInfo, int) }); ok {
+				from := receiver.friendInfo(m.from)
+				receiver.call("Hear", func() { hearAnt.Hear(from, m.msg) })
+			}
+		}
+	}
+}
+
+// hearingAnts returns the friends within the hearing radius of the ant ordered by id.
+func (s *Simulation) hearingAnts(from *AntOS) []*AntOS {
+	var receivers []*AntOS
+	start, end := gmath.NewCircle(from.Position[0], from.Position[1], float64(s.hearingRadius)).ToBox()
+	s.rtree.Search(start, end, func(min, max [2]float64, data GameObject) bool {
+		if receiver, ok := data.(*AntOS); ok && receiver != from && receiver.colony == from.colony &&
+			distance(receiver.Position, from.Position) <= float64(s.hearingRadius) {
+			receivers = append(receivers, receiver)
+		}
+		return true
+	})
+	sort.Slice(receivers, func(i, j int) bool {
+		return receivers[i].GetId() < receivers[j].GetId()
+	})
+	return receivers
+}
+
+func (a *AntOS) friendInfo(friend *AntOS) ant.FriendInfo {
+	return ant.FriendInfo{
+		Id:          friend.GetId(),
+		Role:        friend.GetRole(),
+		CurrentLoad: friend.CurrentSugarLoad,
+		Direction:   gmath.CalculateDirection(a.Position, friend.Position),
+		Distance:    int(distance(a.Position, friend.Position)),
+	}
+}
@@ -0,0 +1,158 @@
+/*
+Copyright (c) 2024 Sebastian Kroczek <me@xbug.de>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package simulation
+
+import (
+	"sort"
+	"strconv"
+)
+
+// EventKind is the kind of an Event.
+type EventKind int
+
+const (
+	TickStarted EventKind = iota
+	TickFinished
+	AntSpawned
+	AntDied
+	SugarSeen
+	SugarTaken
+	SugarDelivered
+	SugarDepleted
+	MarkSet
+	MarkExpired
+)
+
+var eventKindNames = [...]string{
+	TickStarted:    "TickStarted",
+	TickFinished:   "TickFinished",
+	AntSpawned:     "AntSpawned",
+	AntDied:        "AntDied",
+	SugarSeen:      "SugarSeen",
+	SugarTaken:     "SugarTaken",
+	SugarDelivered: "SugarDelivered",
+	SugarDepleted:  "SugarDepleted",
+	MarkSet:        "MarkSet",
+	MarkExpired:    "MarkExpired",
+}
+
+func (k EventKind) String() string {
+	if k >= 0 && int(k) < len(eventKindNames) {
+		return eventKindNames[k]
+	}
+	return "EventKind(" + strconv.Itoa(int(k)) + ")"
+}
+
+// Event is something that happened in a simulation. Only the fields that matter for its kind are set.
+type Event struct {
+	Kind    EventKind
+	Tick    int
+	AntID   int
+	Role    string // the role of the ant for AntSpawned, AntDied and SugarDelivered
+	SugarID int
+	MarkID  int
+	// Amount is the sugar taken or delivered, or the sugar left in a seen pile
+	Amount int
+	// X and Y are where the event happened
+	X, Y float64
+}
+
+// Observer receives the events of a simulation.
+type Observer interface {
+	Observe(Event)
+}
+
+// ObserverFunc is a function that is an Observer.
+type ObserverFunc func(Event)
+
+func (f ObserverFunc) Observe(e Event) {
+	f(e)
+}
+
+// stage is the part of a tick an event happened in, events are delivered ordered by stage first.
+type stage int
+
+const (
+	stageSpawn stage = iota
+	stageUpdate
+	stageSearch
+	stageFlush
+	stageFaults
+)
+
+type pendingEvent struct {
+	Event
+	stage stage
+	id    int
+	seq   int
+}
+
+// emit queues an event to be delivered at the end of the tick. It is safe to call from the ant goroutines.
+func (s *Simulation) emit(e Event) {
+	if len(s.observers) == 0 {
+		return
+	}
+	e.Tick = s.tick
+	id := e.AntID
+	if id == 0 {
+		id = max(e.SugarID, e.MarkID)
+	}
+	s.eventMutex.Lock()
+	defer s.eventMutex.Unlock()
+	s.events = append(s.events, pendingEvent{Event: e, stage: s.stage, id: id, seq: len(s.events)})
+}
+
+func (s *Simulation) notify(e Event) {
+	for _, observer := range s.observers {
+		observer.Observe(e)
+	}
+}
+
+// dispatchEvents delivers the events of the tick. Events that happened concurrently are ordered by stage,
+// then by the ant, sugar or mark they are about and then by kind, so the order does not depend on scheduling.
+func (s *Simulation) dispatchEvents() {
+	if len(s.observers) == 0 {
+		return
+	}
+	s.eventMutex.Lock()
+	events := s.events
+	s.events = nil
+	s.eventMutex.Unlock()
+
+	sort.SliceStable(events, func(i, j int) bool {
+		a, b := events[i], events[j]
+		if a.stage != b.stage {
+			return a.stage < b.stage
+		}
+		if a.id != b.id {
+			return a.id < b.id
+		}
+		if a.Kind != b.Kind {
+			return a.Kind < b.Kind
+		}
+		return a.seq < b.seq
+	})
+	for _, e := range events {
+		s.notify(e.Event)
+	}
+}
@@ -0,0 +1,174 @@
+/*
+Copyright (c) 2024 Sebastian Kroczek <me@xbug.de>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package simulation
+
+import (
+	"fmt"
+	gmath "github.com/gotameme/core/internal/math"
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+	"image/color"
+	"math"
+	"strings"
+)
+
+// Overlay is a set of debug overlays drawn on top of the simulation.
+type Overlay int
+
+const (
+	// OverlayVision draws the vision circle of every ant
+	OverlayVision Overlay = 1 << iota
+	// OverlayBounds draws the bounds and the sight box of every ant and the bounds of the sugar
+	OverlayBounds
+	// OverlayTargets draws a line from every ant to its target
+	OverlayTargets
+	// OverlayMoves draws the direction and the remaining steps of moving ants
+	OverlayMoves
+	// OverlayMarks draws the radius and the information of every mark
+	OverlayMarks
+	// OverlayIndex draws the boxes of the spatial index. The index does not expose its inner nodes, so these are
+	// the boxes of the indexed objects and the bounds of the whole index.
+	OverlayIndex
+	// OverlayHeatmap draws how often the ants were in each part of the world
+	OverlayHeatmap
+)
+
+var overlayNames = []string{"vision", "bounds", "targets", "moves", "marks", "index", "heatmap"}
+
+// Overlays lists every overlay in the order of their names.
+var Overlays = []Overlay{OverlayVision, OverlayBounds, OverlayTargets, OverlayMoves, OverlayMarks, OverlayIndex,
+	OverlayHeatmap}
+
+func (o Overlay) String() string {
+	var names []string
+	for i, overlay := range Overlays {
+		if o&overlay != 0 {
+			names = append(names, overlayNames[i])
+		}
+	}
+	return strings.Join(names, ",")
+}
+
+var (
+	boundsColor = color.RGBA{R: 0x20, G: 0x20, B: 0xff, A: 0xff}
+	sightColor  = color.RGBA{R: 0x80, G: 0x80, B: 0xff, A: 0x80}
+	sugarColor  = color.RGBA{R: 0xf5, G: 0xf5, B: 0xdc, A: 0xff}
+	targetColor = color.RGBA{R: 0xff, G: 0x80, B: 0x00, A: 0xff}
+	moveColor   = color.RGBA{R: 0xff, G: 0x00, B: 0xff, A: 0xff}
+	markColor   = color.RGBA{R: 0x80, G: 0x80, B: 0x00, A: 0xff}
+	indexColor  = color.RGBA{R: 0x40, G: 0x40, B: 0x40, A: 0xff}
+)
+
+// ToggleOverlay switches the given overlays on or off.
+func (s *Simulation) ToggleOverlay(overlay Overlay) {
+	s.overlays ^= overlay
+}
+
+// Overlay returns the overlays that are switched on.
+func (s *Simulation) Overlay() Overlay {
+	return s.overlays
+}
+
+func (s *Simulation) drawOverlays(screen *ebiten.Image) {
+	if s.overlays&OverlayHeatmap != 0 {
+		s.drawHeatmap(screen)
+	}
+	if s.overlays&OverlayIndex != 0 {
+		s.rtree.Scan(func(min, max [2]float64, _ GameObject) bool {
+			strokeBox(screen, min, max, indexColor)
+			return true
+		})
+		min, max := s.rtree.Bounds()
+		strokeBox(screen, min, max, indexColor)
+	}
+	if s.overlays&OverlayMarks != 0 {
+		for _, m := range s.marks {
+			vector.StrokeCircle(screen, float32(m.Position[0]), float32(m.Position[1]), float32(m.Radius), 1, markColor, true)
+			ebitenutil.DebugPrintAt(screen, fmt.Sprint(m.Information), int(m.Position[0]), int(m.Position[1]))
+		}
+	}
+	if s.overlays&OverlayBounds != 0 {
+		for _, sugar := range s.sugar {
+			min, max := sugar.Rect.ToBox()
+			strokeBox(screen, min, max, sugarColor)
+		}
+	}
+	for _, a := range s.ants {
+		a.drawOverlays(screen, s.overlays)
+	}
+}
+
+func (a *AntOS) drawOverlays(screen *ebiten.Image, overlays Overlay) {
+	x, y := float32(a.Position[0]), float32(a.Position[1])
+	if overlays&OverlayVision != 0 && a.visionImage != nil {
+		op := &ebiten.DrawImageOptions{}
+		op.GeoM.Translate(a.Position[0]-float64(a.Vision), a.Position[1]-float64(a.Vision))
+		screen.DrawImage(a.visionImage, op)
+	}
+	if overlays&OverlayBounds != 0 {
+		min, max := a.sightBox()
+		strokeBox(screen, min, max, sightColor)
+		min, max = a.bounds()
+		strokeBox(screen, min, max, boundsColor)
+	}
+	if overlays&OverlayTargets != 0 {
+		switch target := a.Target.(type) {
+		case *Sugar:
+			vector.StrokeLine(screen, x, y, float32(target.Position[0]), float32(target.Position[1]), 1, targetColor, true)
+		case *AntHill:
+			vector.StrokeLine(screen, x, y, float32(target.Position[0]), float32(target.Position[1]), 1, targetColor, true)
+		}
+	}
+	if moving, ok := a.State.(*AntOSMoving); ok && overlays&OverlayMoves != 0 {
+		direction := a.CurrentDirection
+		if moving.TargetDirection != nil {
+			direction = *moving.TargetDirection
+		}
+		steps := 0
+		if moving.Steps != nil {
+			steps = *moving.Steps
+		}
+		// steps are a distance, every update moves the ant by its speed
+		length := float64(steps)
+		vector.StrokeLine(screen, x, y,
+			x+float32(length*math.Cos(direction*gmath.DegToRad)), y+float32(length*math.Sin(direction*gmath.DegToRad)),
+			1, moveColor, true)
+		ebitenutil.DebugPrintAt(screen, fmt.Sprintf("%d %.0f°", steps, direction), int(x)+8, int(y)+8)
+	}
+}
+
+// drawHeatmap draws the heatmap scaled to the world, the image is only updated once per tick.
+func (s *Simulation) drawHeatmap(screen *ebiten.Image) {
+	if s.heatmapImage == nil || s.heatmapTick != s.tick {
+		s.heatmapImage = ebiten.NewImageFromImage(s.heatmap.Image())
+		s.heatmapTick = s.tick
+	}
+	op := &ebiten.DrawImageOptions{}
+	op.GeoM.Scale(float64(s.heatmap.CellSize()), float64(s.heatmap.CellSize()))
+	screen.DrawImage(s.heatmapImage, op)
+}
+
+func strokeBox(screen *ebiten.Image, min, max [2]float64, clr color.RGBA) {
+	vector.StrokeRect(screen, float32(min[0]), float32(min[1]), float32(max[0]-min[0]), float32(max[1]-min[1]), 1, clr, false)
+}
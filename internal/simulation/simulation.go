@@ -22,12 +22,16 @@ THE SOFTWARE.
 package simulation
 
 import (
-	"fmt"
+	"github.com/gotameme/core/ant"
+	"github.com/gotameme/core/internal/helper"
+	"github.com/gotameme/core/rand"
 	"github.com/hajimehoshi/ebiten/v2"
-	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
 	"github.com/paulmach/orb"
 	"github.com/tidwall/rtree"
 	"image/color"
+	"log/slog"
+	"math"
+	"sort"
 	"sync"
 )
 
@@ -36,12 +40,36 @@ type Simulation struct {
 	rtree                     *rtree.RTreeG[GameObject]
 	addMarkingQueue           []*Marking
 	removeMarkingQueue        []*Marking
+	messageQueue              []message
 	queueMutex                sync.Mutex
+	tick                      int
+	lastAntID                 int
+	lastSugarID               int
+	lastMarkID                int
+	antsLost                  int
+	firstDelivery             int
+	rand                      *rand.Rand
+	markCache                 *markCache
+	markingPool               *markingPool
+	circles                   *helper.CircleCache
+	stage                     stage
+	events                    []pendingEvent
+	eventMutex                sync.Mutex
+	logger                    *slog.Logger
+	antLogger                 *slog.Logger
+	overlays                  Overlay
+	heatmap                   *Heatmap
+	heatmapImage              *ebiten.Image
+	heatmapTick               int
+	faults                    []*Fault
+	faultyAnts                []*AntOS
+	abort                     *Fault
 
 	RolesCount map[string]int
 	SimulationConfig
 
 	antHill *AntHill
+	colony  *Colony
 	apple   *Apple
 	ants    []*AntOS
 	marks   []*Marking
@@ -50,15 +78,33 @@ type Simulation struct {
 
 func NewSimulation(screenWidth, screenHeight int, cnf SimulationConfig) *Simulation {
 	var rTree = rtree.RTreeG[GameObject]{}
-	var antHill = NewRandomAntHill(screenWidth, screenHeight, 30)
-	var apple = NewRandomApple(screenWidth, screenHeight, 30)
+	var seed = cnf.seed
+	if seed == 0 {
+		seed = rand.NewSeed()
+	}
+	var r = rand.New(seed)
+	var logger = newLogger(cnf.logHandler, cnf.logLevel)
+	var antLogger = logger
+	if cnf.antLogHandler != nil {
+		antLogger = newLogger(cnf.antLogHandler, cnf.logLevel)
+	}
+	var antHill = NewRandomAntHill(r, screenWidth, screenHeight, 30)
+	var apple = NewRandomApple(r, screenWidth, screenHeight, 30)
 	rTree.Insert(antHill.Bounds())
 	simulation := &Simulation{
 		screenWidth:      screenWidth,
 		screenHeight:     screenHeight,
 		antHill:          antHill,
+		colony:           NewColony(cnf),
 		apple:            apple,
 		rtree:            &rTree,
+		rand:             r,
+		markCache:        newMarkCache(),
+		markingPool:      newMarkingPool(),
+		circles:          helper.NewCircleCache(),
+		logger:           logger,
+		heatmap:          newHeatmap(screenWidth, screenHeight, cnf.heatmapCellSize, cnf.heatmapSplit),
+		antLogger:        antLogger,
 		SimulationConfig: cnf,
 		RolesCount:       make(map[string]int),
 	}
@@ -67,6 +113,16 @@ func NewSimulation(screenWidth, screenHeight int, cnf SimulationConfig) *Simulat
 }
 
 func (s *Simulation) Update() error {
+	if s.abort != nil {
+		return s.abort
+	}
+	s.tick++
+	s.notify(Event{Kind: TickStarted, Tick: s.tick})
+	s.stage = stageSpawn
+	s.colony.budget.startTick(s.tick)
+	faultsBefore := len(s.Faults())
+	s.tickColonyController()
+
 	if len(s.ants) < s.antDesiredValue {
 		for i := 0; i < s.antDesiredValue-len(s.ants); i++ {
 			s.AddNewAnt()
@@ -81,6 +137,37 @@ func (s *Simulation) Update() error {
 			s.sugar = append(s.sugar, sugar)
 		}
 	}
+	if s.deterministic {
+		s.updateSequential()
+	} else {
+		s.updateParallel()
+	}
+	s.recordPositions()
+	s.stage = stageFlush
+	for _, mark := range s.marks {
+		mark.Update()
+	}
+	s.FlushMarkingChanges()
+	s.FlushMessages()
+	s.colony.Flush()
+	s.colony.budget.endTick()
+	s.stage = stageFaults
+	err := s.handleFaults(faultsBefore)
+	s.dispatchEvents()
+	s.notify(Event{Kind: TickFinished, Tick: s.tick})
+	if err != nil {
+		return err
+	}
+	for _, recorder := range s.tickRecorders {
+		if err := recorder.RecordTick(s); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Simulation) updateParallel() {
+	s.stage = stageUpdate
 	wg := sync.WaitGroup{}
 	wg.Add(len(s.ants))
 	mu := sync.Mutex{}
@@ -105,6 +192,7 @@ func (s *Simulation) Update() error {
 	}
 	wg.Wait()
 
+	s.stage = stageSearch
 	for _, antOS := range s.ants {
 		wg.Add(1)
 		go func(antOS *AntOS) {
@@ -128,11 +216,67 @@ func (s *Simulation) Update() error {
 		}
 	}
 	wg.Wait()
-	for _, mark := range s.marks {
-		mark.Update()
+}
+
+// updateSequential updates the ants one after another in the order they were added,
+// and hands them what they see sorted, so a run does not depend on scheduling or the shape of the tree.
+func (s *Simulation) updateSequential() {
+	s.stage = stageUpdate
+	for _, antOS := range s.ants {
+		oldMin, oldMax := antOS.Bounds()
+		antOS.Update()
+		newMin, newMax := antOS.Bounds()
+		s.rtree.Replace(oldMin, oldMax, antOS, newMin, newMax, antOS)
+	}
+	// sugar removes itself when it is empty
+	for _, sugar := range append([]*Sugar(nil), s.sugar...) {
+		sugar.Update()
+	}
+	s.stage = stageSearch
+	for _, antOS := range s.ants {
+		s.searchSorted(antOS.See())
+		s.searchSorted(antOS.Smell())
+		if antOS.Target != nil {
+			s.searchSorted(antOS.Collides())
+		}
 	}
-	s.FlushMarkingChanges()
-	return nil
+}
+
+// searchSorted is like rtree.Search, but iterates the objects ordered by their kind and id.
+func (s *Simulation) searchSorted(min, max [2]float64, iter SearchIter) {
+	type hit struct {
+		min, max [2]float64
+		data     GameObject
+	}
+	var hits []hit
+	s.rtree.Search(min, max, func(min, max [2]float64, data GameObject) bool {
+		hits = append(hits, hit{min, max, data})
+		return true
+	})
+	sort.Slice(hits, func(i, j int) bool {
+		ki, ii := objectKey(hits[i].data)
+		kj, ij := objectKey(hits[j].data)
+		return ki < kj || ki == kj && ii < ij
+	})
+	for _, h := range hits {
+		if !iter(h.min, h.max, h.data) {
+			return
+		}
+	}
+}
+
+func objectKey(o GameObject) (kind, id int) {
+	switch o := o.(type) {
+	case *AntHill:
+		return 0, 0
+	case *AntOS:
+		return 1, o.id
+	case *Sugar:
+		return 2, o.id
+	case *Marking:
+		return 3, o.id
+	}
+	return 4, 0
 }
 
 func (s *Simulation) Draw(screen *ebiten.Image) {
@@ -148,31 +292,135 @@ func (s *Simulation) Draw(screen *ebiten.Image) {
 		sugar.Draw(screen)
 	}
 	s.antHill.Draw(screen)
+	s.drawOverlays(screen)
 	// s.apple.Draw(screen)
-	// x, y := ebiten.CursorPosition()
-	msg := fmt.Sprintf("TPS: %0.2f\nFPS: %0.2f\nLen: %d\nSugar: %d", ebiten.ActualTPS(), ebiten.ActualFPS(), s.rtree.Len(), s.antHill.CurrentSugar)
-	ebitenutil.DebugPrint(screen, msg)
 }
 
 func (s *Simulation) Layout(_, _ int) (int, int) {
 	return s.screenWidth, s.screenHeight
 }
 
+// Tick returns the number of the current tick.
+func (s *Simulation) Tick() int {
+	return s.tick
+}
+
+// Seed returns the seed of the random numbers, a simulation with the same seed places its hill and sugar at the same positions.
+func (s *Simulation) Seed() uint64 {
+	return s.rand.Seed()
+}
+
+// TimingReports returns how much time the colonies spent in their callbacks.
+func (s *Simulation) TimingReports() []TimingReport {
+	return []TimingReport{s.colony.TimingReport()}
+}
+
+// Ant returns the living ant with the given id or nil.
+func (s *Simulation) Ant(id int) *AntOS {
+	for _, a := range s.ants {
+		if a.id == id {
+			return a
+		}
+	}
+	return nil
+}
+
+// AntAt returns the ant closest to the given position within radius or nil.
+func (s *Simulation) AntAt(x, y, radius float64) *AntOS {
+	var closest *AntOS
+	for _, a := range s.ants {
+		if distance := math.Hypot(a.Position[0]-x, a.Position[1]-y); distance <= radius {
+			closest, radius = a, distance
+		}
+	}
+	return closest
+}
+
+func (s *Simulation) ColonyStats() ant.ColonyStats {
+	stats := ant.ColonyStats{
+		Tick:       s.tick,
+		Ants:       len(s.ants),
+		RolesCount: make(ant.RolesCount, len(s.RolesCount)),
+		Sugar:      s.antHill.CurrentSugar,
+		Marks:      len(s.marks),
+	}
+	for role, count := range s.RolesCount {
+		stats.RolesCount[role] = count
+	}
+	for _, antOS := range s.ants {
+		if antOS.State == nil {
+			stats.IdleAnts++
+		}
+		stats.SugarInTransit += antOS.CurrentSugarLoad
+	}
+	return stats
+}
+
+// RemainingSugar returns the sugar left in the world, not counting the sugar the ants carry.
+func (s *Simulation) RemainingSugar() int {
+	remaining := 0
+	for _, sugar := range s.sugar {
+		remaining += sugar.CurrentSugar
+	}
+	return remaining
+}
+
+// Score is the sugar a colony delivered to its hill.
+type Score struct {
+	Colony string
+	Sugar  int
+}
+
+// Scores returns the score of every colony in the simulation.
+func (s *Simulation) Scores() []Score {
+	return []Score{{Colony: s.colony.name, Sugar: s.antHill.CurrentSugar}}
+}
+
+// Statistics sum up the outcome of a simulation so far.
+type Statistics struct {
+	SugarDelivered int `json:"sugarDelivered"`
+	AntsLost       int `json:"antsLost"`
+	// FirstDelivery is the tick sugar was delivered to the hill for the first time, 0 if it never was
+	FirstDelivery int `json:"firstDelivery"`
+}
+
+func (s *Simulation) Statistics() Statistics {
+	return Statistics{
+		SugarDelivered: s.antHill.CurrentSugar,
+		AntsLost:       s.antsLost,
+		FirstDelivery:  s.firstDelivery,
+	}
+}
+
+func (s *Simulation) recordDelivery() {
+	s.queueMutex.Lock()
+	defer s.queueMutex.Unlock()
+	if s.firstDelivery == 0 {
+		s.firstDelivery = s.tick
+	}
+}
+
 func (s *Simulation) AddNewAnt() {
-	roleName := s.chooseRole(s.RolesCount)
+	roleName := s.colony.SpawnRole()
+	if _, ok := s.roles[roleName]; !ok {
+		roleName = s.chooseRole(s.RolesCount)
+	}
 	antProperties := s.defaultRoleProperties
 	if properties, ok := s.roles[roleName]; ok {
 		antProperties = properties
 		s.RolesCount[roleName]++
 	}
-	antOS := NewAntOS(s, WithAntHill(s.antHill), WithRole(roleName, antProperties))
+	antOS := NewAntOS(s, WithAntHill(s.antHill), WithColony(s.colony), WithRole(roleName, antProperties))
 
-	newAnt := s.antConstructor(antOS)
-	antOS.Init(newAnt)
+	antOS.call("AntConstructor", func() {
+		antOS.Init(s.antConstructor(antOS))
+	})
 
 	s.ants = append(s.ants, antOS)
 	newMin, newMax := antOS.Bounds()
 	s.rtree.Insert(newMin, newMax, antOS)
+	s.colony.addEvent(colonyEvent{kind: antSpawned, antID: antOS.GetId(), role: roleName})
+	s.emit(Event{Kind: AntSpawned, AntID: antOS.id, Role: roleName, X: antOS.Position[0], Y: antOS.Position[1]})
 }
 
 func (s *Simulation) RemoveAnt(ant *AntOS) {
@@ -181,6 +429,13 @@ func (s *Simulation) RemoveAnt(ant *AntOS) {
 			s.ants = append(s.ants[:i], s.ants[i+1:]...)
 			vmin, vmax := ant.Bounds()
 			s.rtree.Delete(vmin, vmax, ant)
+			if _, ok := s.roles[ant.role]; ok {
+				s.RolesCount[ant.role]--
+			}
+			s.markCache.RemoveAnt(ant)
+			s.antsLost++
+			ant.colony.addEvent(colonyEvent{kind: antDied, antID: ant.GetId()})
+			s.emit(Event{Kind: AntDied, AntID: ant.id, Role: ant.role, X: ant.Position[0], Y: ant.Position[1]})
 			return
 		}
 	}
@@ -216,7 +471,14 @@ func (s *Simulation) RemoveMarking(m *Marking) {
 func (s *Simulation) FlushMarkingChanges() {
 	s.queueMutex.Lock()
 	defer s.queueMutex.Unlock()
+	// marks are queued concurrently, so sort them by their ants to number them in a deterministic order
+	sort.SliceStable(s.addMarkingQueue, func(i, j int) bool {
+		return s.addMarkingQueue[i].antID < s.addMarkingQueue[j].antID
+	})
 	for _, m := range s.addMarkingQueue {
+		s.lastMarkID++
+		m.id = s.lastMarkID
+		s.emit(Event{Kind: MarkSet, AntID: m.antID, MarkID: m.id, X: m.Position[0], Y: m.Position[1]})
 		s.rtree.Insert(m.Bounds())
 		s.marks = append(s.marks, m)
 	}
@@ -226,12 +488,13 @@ func (s *Simulation) FlushMarkingChanges() {
 		for i, mark := range s.marks {
 			if mark == m {
 				s.marks = append(s.marks[:i], s.marks[i+1:]...)
-				MarkCache.RemoveMark(m)
+				s.markCache.RemoveMark(m)
+				s.emit(Event{Kind: MarkExpired, MarkID: m.id, X: m.Position[0], Y: m.Position[1]})
 				s.rtree.Delete(m.Bounds())
 				break
 			}
 		}
-		PutMarking(m)
+		s.markingPool.Put(m)
 	}
 	s.removeMarkingQueue = nil
 }
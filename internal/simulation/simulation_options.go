@@ -21,7 +21,12 @@ THE SOFTWARE.
 */
 package simulation
 
-import "github.com/gotameme/core/ant"
+import (
+	"github.com/gotameme/core/ant"
+	"log/slog"
+	"sort"
+	"time"
+)
 
 type SimulationConfig struct {
 	// antDesiredValue defines how many ants should be in the simulation simultaneously
@@ -35,6 +40,44 @@ type SimulationConfig struct {
 	// roles is a map of roles and their properties
 	roles                 map[string]Properties
 	defaultRoleProperties Properties
+	// colonyName is the name of the colony, it is used to report faults
+	colonyName string
+	// colonyMemoryLimit defines how many keys the colony memory can hold, 0 means unlimited
+	colonyMemoryLimit int
+	// colonyController is an optional controller that is ticked once per tick for the whole colony
+	colonyController ant.ColonyController
+	// hearingRadius defines how far a message said by an ant can be heard
+	hearingRadius int
+	// messageBandwidth defines how many messages an ant can say per tick
+	messageBandwidth int
+	// faultPolicy defines how to handle ants whose callbacks panic
+	faultPolicy FaultPolicy
+	// callbackBudget is the time a single callback may take, 0 means unlimited
+	callbackBudget time.Duration
+	// tickBudget is the time the callbacks of a colony may take per tick, 0 means unlimited
+	tickBudget time.Duration
+	// budgetPolicy defines how to handle colonies that exceed their budgets
+	budgetPolicy BudgetPolicy
+	// penaltyTicks defines how many ticks an offender sits out with PenaliseOffender
+	penaltyTicks int
+	// seed for the random numbers of the simulation, 0 means a random seed
+	seed uint64
+	// tickRecorders are called after every tick, e.g. to record a replay
+	tickRecorders []TickRecorder
+	// deterministic updates the ants sequentially, so runs with the same seed give the same result
+	deterministic bool
+	// observers receive the events of the simulation
+	observers []Observer
+	// logHandler handles the log of the engine, the default handler of slog if nil
+	logHandler slog.Handler
+	logLevel   slog.Leveler
+	// antLogHandler handles the log of the ants, the handler of the engine if nil
+	antLogHandler slog.Handler
+	// antLogSize is the number of log entries kept per ant
+	antLogSize int
+	// heatmapCellSize and heatmapSplit define the grid and the layers of the heatmap
+	heatmapCellSize int
+	heatmapSplit    HeatmapSplit
 }
 
 func NewSimulationConfig(options ...SimulationOptions) *SimulationConfig {
@@ -49,6 +92,12 @@ func NewSimulationConfig(options ...SimulationOptions) *SimulationConfig {
 		},
 		// TODO: We need the real default range, which is half the diagonal of the field
 		defaultRoleProperties: NewDefaultProperties(100),
+		colonyName:            "colony",
+		hearingRadius:         100,
+		messageBandwidth:      1,
+		logLevel:              slog.LevelInfo,
+		antLogSize:            defaultAntLogSize,
+		heatmapCellSize:       defaultHeatmapCellSize,
 	}
 
 	for _, o := range options {
@@ -84,3 +133,131 @@ func WithRoles(roles map[string]Properties, chooseRole ant.ChooseRole) Simulatio
 		s.chooseRole = chooseRole
 	}
 }
+
+func WithColonyMemoryLimit(limit int) SimulationOptions {
+	return func(s *SimulationConfig) {
+		s.colonyMemoryLimit = limit
+	}
+}
+
+func WithColonyController(controller ant.ColonyController) SimulationOptions {
+	return func(s *SimulationConfig) {
+		s.colonyController = controller
+	}
+}
+
+func WithHearingRadius(radius int) SimulationOptions {
+	return func(s *SimulationConfig) {
+		s.hearingRadius = radius
+	}
+}
+
+func WithMessageBandwidth(messages int) SimulationOptions {
+	return func(s *SimulationConfig) {
+		s.messageBandwidth = messages
+	}
+}
+
+func WithColonyName(name string) SimulationOptions {
+	return func(s *SimulationConfig) {
+		s.colonyName = name
+	}
+}
+
+func WithFaultPolicy(policy FaultPolicy) SimulationOptions {
+	return func(s *SimulationConfig) {
+		s.faultPolicy = policy
+	}
+}
+
+func WithBudgets(callbackBudget, tickBudget time.Duration, policy BudgetPolicy, penaltyTicks int) SimulationOptions {
+	return func(s *SimulationConfig) {
+		s.callbackBudget = callbackBudget
+		s.tickBudget = tickBudget
+		s.budgetPolicy = policy
+		s.penaltyTicks = penaltyTicks
+	}
+}
+
+func WithSeed(seed uint64) SimulationOptions {
+	return func(s *SimulationConfig) {
+		s.seed = seed
+	}
+}
+
+func WithTickRecorder(recorder TickRecorder) SimulationOptions {
+	return func(s *SimulationConfig) {
+		// options can be applied more than once, a recorder must not record twice
+		for _, r := range s.tickRecorders {
+			if r == recorder {
+				return
+			}
+		}
+		s.tickRecorders = append(s.tickRecorders, recorder)
+	}
+}
+
+func WithDeterministicUpdates() SimulationOptions {
+	return func(s *SimulationConfig) {
+		s.deterministic = true
+	}
+}
+
+func WithObserver(observer Observer) SimulationOptions {
+	return func(s *SimulationConfig) {
+		s.observers = append(s.observers, observer)
+	}
+}
+
+func WithLogHandler(handler slog.Handler) SimulationOptions {
+	return func(s *SimulationConfig) {
+		s.logHandler = handler
+	}
+}
+
+func WithLogLevel(level slog.Leveler) SimulationOptions {
+	return func(s *SimulationConfig) {
+		s.logLevel = level
+	}
+}
+
+func WithAntLogHandler(handler slog.Handler) SimulationOptions {
+	return func(s *SimulationConfig) {
+		s.antLogHandler = handler
+	}
+}
+
+func WithAntLogSize(size int) SimulationOptions {
+	return func(s *SimulationConfig) {
+		s.antLogSize = size
+	}
+}
+
+func WithHeatmap(cellSize int, split HeatmapSplit) SimulationOptions {
+	return func(s *SimulationConfig) {
+		s.heatmapCellSize = cellSize
+		s.heatmapSplit = split
+	}
+}
+
+func (s SimulationConfig) ColonyName() string {
+	return s.colonyName
+}
+
+func (s SimulationConfig) AntDesiredValue() int {
+	return s.antDesiredValue
+}
+
+func (s SimulationConfig) SugarDesiredValue() int {
+	return s.sugarDesiredValue
+}
+
+// Roles returns the names of the configured roles, sorted.
+func (s SimulationConfig) Roles() []string {
+	roles := make([]string, 0, len(s.roles))
+	for role := range s.roles {
+		roles = append(roles, role)
+	}
+	sort.Strings(roles)
+	return roles
+}
@@ -0,0 +1,342 @@
+/*
+Copyright (c) 2024 Sebastian Kroczek <me@xbug.de>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package simulation
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/binary"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"github.com/gotameme/core/ant"
+	"github.com/paulmach/orb"
+	"github.com/tidwall/rtree"
+	"io"
+)
+
+// A saved simulation starts with the magic "GTMS" and a big endian uint16 version, followed by a gzip compressed gob.
+const (
+	snapshotMagic   = "GTMS"
+	snapshotVersion = 1
+)
+
+var ErrNoSnapshot = errors.New("not a saved simulation")
+
+type targetKind int
+
+const (
+	noTarget targetKind = iota
+	hillTarget
+	sugarTarget
+)
+
+type snapshot struct {
+	ScreenWidth, ScreenHeight int
+	Tick                      int
+	LastAntID                 int
+	LastSugarID               int
+	LastMarkID                int
+	AntsLost                  int
+	FirstDelivery             int
+	Rand                      []byte
+	Hill                      HillState
+	Ants                      []antSnapshot
+	Sugar                     []SugarState
+	Marks                     []markSnapshot
+	Colony                    colonySnapshot
+}
+
+type antSnapshot struct {
+	AntState
+	Properties       Properties
+	SetMarkThreshold int
+	SetMarkResetTime int
+	Disabled         bool
+	// Moving is set if the ant is in the AntOSMoving state
+	Moving          bool
+	TargetDirection *float64
+	Steps           *int
+	Target          targetKind
+	TargetId        int
+	// SeenMarks are the ids of the marks the ant already smelled, see markCache
+	SeenMarks []int
+	// Data is the state of an ant.Serializable ant
+	Data []byte
+}
+
+type markSnapshot struct {
+	MarkState
+	Lifespan int
+}
+
+type colonySnapshot struct {
+	Memory    map[string]interface{}
+	Order     int
+	SpawnRole string
+	Events    []eventSnapshot
+	// Controller is the state of an ant.Serializable controller
+	Controller []byte
+}
+
+type eventSnapshot struct {
+	Kind   int
+	AntID  int
+	Role   string
+	Amount int
+}
+
+// Save writes the full state of the simulation between two ticks to w.
+// Values in the colony memory must be known to gob, custom types have to be registered with gob.Register.
+func (s *Simulation) Save(w io.Writer) error {
+	snap, err := s.snapshot()
+	if err != nil {
+		return err
+	}
+	buf := bufio.NewWriter(w)
+	if _, err = buf.WriteString(snapshotMagic); err != nil {
+		return err
+	}
+	if err = binary.Write(buf, binary.BigEndian, uint16(snapshotVersion)); err != nil {
+		return err
+	}
+	gz := gzip.NewWriter(buf)
+	if err = gob.NewEncoder(gz).Encode(snap); err != nil {
+		return err
+	}
+	if err = gz.Close(); err != nil {
+		return err
+	}
+	return buf.Flush()
+}
+
+// Restore replaces the state of the simulation with a state written by Save.
+// The simulation must be created with the configuration of the saved simulation, because the ants are
+// constructed anew with its AntConstructor. Continuing a restored simulation with deterministic updates
+// gives the same result as a run that was never interrupted, as long as the ants are ant.Serializable.
+func (s *Simulation) Restore(r io.Reader) error {
+	buf := bufio.NewReader(r)
+	magic := make([]byte, len(snapshotMagic))
+	if _, err := io.ReadFull(buf, magic); err != nil || string(magic) != snapshotMagic {
+		return ErrNoSnapshot
+	}
+	var version uint16
+	if err := binary.Read(buf, binary.BigEndian, &version); err != nil {
+		return ErrNoSnapshot
+	}
+	if version != snapshotVersion {
+		return fmt.Errorf("unsupported simulation version %d, expected %d", version, snapshotVersion)
+	}
+	gz, err := gzip.NewReader(buf)
+	if err != nil {
+		return err
+	}
+	defer gz.Close()
+	var snap snapshot
+	if err = gob.NewDecoder(gz).Decode(&snap); err != nil {
+		return err
+	}
+	if snap.ScreenWidth != s.screenWidth || snap.ScreenHeight != s.screenHeight {
+		return fmt.Errorf("saved simulation is %dx%d, but the simulation is %dx%d",
+			snap.ScreenWidth, snap.ScreenHeight, s.screenWidth, s.screenHeight)
+	}
+	return s.restore(snap)
+}
+
+func (s *Simulation) snapshot() (snapshot, error) {
+	state := s.State()
+	snap := snapshot{
+		ScreenWidth:   s.screenWidth,
+		ScreenHeight:  s.screenHeight,
+		Tick:          s.tick,
+		LastAntID:     s.lastAntID,
+		LastSugarID:   s.lastSugarID,
+		LastMarkID:    s.lastMarkID,
+		AntsLost:      s.antsLost,
+		FirstDelivery: s.firstDelivery,
+		Hill:          state.Hill,
+		Sugar:         state.Sugar,
+	}
+	var err error
+	if snap.Rand, err = s.rand.MarshalBinary(); err != nil {
+		return snap, err
+	}
+	for i, m := range s.marks {
+		snap.Marks = append(snap.Marks, markSnapshot{MarkState: state.Marks[i], Lifespan: m.Lifespan})
+	}
+	for i, a := range s.ants {
+		as := antSnapshot{
+			AntState:         state.Ants[i],
+			Properties:       a.Properties,
+			SetMarkThreshold: a.SetMarkThreshold,
+			SetMarkResetTime: a.SetMarkResetTime,
+			Disabled:         a.IsDisabled(),
+		}
+		if moving, ok := a.State.(*AntOSMoving); ok {
+			as.Moving = true
+			as.TargetDirection = moving.TargetDirection
+			as.Steps = moving.Steps
+		}
+		switch target := a.Target.(type) {
+		case *AntHill:
+			as.Target = hillTarget
+		case *Sugar:
+			as.Target = sugarTarget
+			as.TargetId = target.id
+		}
+		for _, m := range s.marks {
+			if s.markCache.HasMark(a, m) {
+				as.SeenMarks = append(as.SeenMarks, m.id)
+			}
+		}
+		if serializable, ok := a.ant.(ant.Serializable); ok {
+			if as.Data, err = serializable.MarshalBinary(); err != nil {
+				return snap, fmt.Errorf("ant %d: %w", a.id, err)
+			}
+		}
+		snap.Ants = append(snap.Ants, as)
+	}
+
+	c := s.colony
+	c.mu.RLock()
+	snap.Colony = colonySnapshot{
+		Memory:    c.memory,
+		Order:     c.order,
+		SpawnRole: c.spawnRole,
+	}
+	for _, e := range c.events {
+		snap.Colony.Events = append(snap.Colony.Events, eventSnapshot{int(e.kind), e.antID, e.role, e.amount})
+	}
+	c.mu.RUnlock()
+	if serializable, ok := c.controller.(ant.Serializable); ok {
+		if snap.Colony.Controller, err = serializable.MarshalBinary(); err != nil {
+			return snap, fmt.Errorf("colony controller: %w", err)
+		}
+	}
+	return snap, nil
+}
+
+func (s *Simulation) restore(snap snapshot) error {
+	if err := s.rand.UnmarshalBinary(snap.Rand); err != nil {
+		return err
+	}
+	for _, a := range s.ants {
+		s.markCache.RemoveAnt(a)
+	}
+	s.tick = snap.Tick
+	s.antsLost = snap.AntsLost
+	s.firstDelivery = snap.FirstDelivery
+	s.rtree = &rtree.RTreeG[GameObject]{}
+	s.antHill = NewAntHill(s.screenWidth, s.screenHeight, orb.Point{snap.Hill.X, snap.Hill.Y})
+	s.antHill.CurrentSugar = snap.Hill.Sugar
+	s.rtree.Insert(s.antHill.Bounds())
+
+	s.sugar = nil
+	sugars := make(map[int]*Sugar, len(snap.Sugar))
+	for _, st := range snap.Sugar {
+		sugar := NewSugar(s, orb.Point{st.X, st.Y})
+		sugar.id = st.Id
+		sugar.CurrentSugar = st.Amount
+		s.rtree.Insert(sugar.Bounds())
+		s.sugar = append(s.sugar, sugar)
+		sugars[sugar.id] = sugar
+	}
+	s.lastSugarID = snap.LastSugarID
+
+	s.marks = nil
+	marks := make(map[int]*Marking, len(snap.Marks))
+	for _, st := range snap.Marks {
+		m := NewMarking(s, orb.Point{st.X, st.Y}, st.Radius, st.Information)
+		m.id = st.Id
+		m.Lifespan = st.Lifespan
+		s.rtree.Insert(m.Bounds())
+		s.marks = append(s.marks, m)
+		marks[m.id] = m
+	}
+	s.lastMarkID = snap.LastMarkID
+
+	s.ants = nil
+	s.RolesCount = make(map[string]int)
+	for _, as := range snap.Ants {
+		antOS := NewAntOS(s, WithAntHill(s.antHill), WithColony(s.colony), WithRole(as.Role, as.Properties),
+			WithPosition(as.X, as.Y))
+		antOS.id = as.Id
+		antOS.CurrentDirection = as.Direction
+		antOS.CurrentSugarLoad = as.Load
+		antOS.SetMarkThreshold = as.SetMarkThreshold
+		antOS.SetMarkResetTime = as.SetMarkResetTime
+		antOS.disabled.Store(as.Disabled)
+		if as.Moving {
+			antOS.State = &AntOSMoving{TargetDirection: as.TargetDirection, Steps: as.Steps}
+		}
+		switch as.Target {
+		case hillTarget:
+			antOS.Target = s.antHill
+		case sugarTarget:
+			if sugar, ok := sugars[as.TargetId]; ok {
+				antOS.Target = sugar
+			}
+		}
+		for _, id := range as.SeenMarks {
+			if m, ok := marks[id]; ok {
+				s.markCache.AddMark(antOS, m)
+			}
+		}
+		if _, ok := s.roles[as.Role]; ok {
+			s.RolesCount[as.Role]++
+		}
+		antOS.call("AntConstructor", func() {
+			antOS.Init(s.antConstructor(antOS))
+		})
+		if serializable, ok := antOS.ant.(ant.Serializable); ok && as.Data != nil {
+			if err := serializable.UnmarshalBinary(as.Data); err != nil {
+				return fmt.Errorf("ant %d: %w", as.Id, err)
+			}
+		}
+		s.ants = append(s.ants, antOS)
+		newMin, newMax := antOS.Bounds()
+		s.rtree.Insert(newMin, newMax, antOS)
+	}
+	s.lastAntID = snap.LastAntID
+
+	c := s.colony
+	c.mu.Lock()
+	c.memory = snap.Colony.Memory
+	if c.memory == nil {
+		c.memory = make(map[string]interface{})
+	}
+	c.writes = make(map[string]colonyWrite)
+	c.order = snap.Colony.Order
+	c.spawnRole = snap.Colony.SpawnRole
+	c.events = nil
+	for _, e := range snap.Colony.Events {
+		c.events = append(c.events, colonyEvent{colonyEventKind(e.Kind), e.AntID, e.Role, e.Amount})
+	}
+	c.mu.Unlock()
+	if serializable, ok := c.controller.(ant.Serializable); ok && snap.Colony.Controller != nil {
+		if err := serializable.UnmarshalBinary(snap.Colony.Controller); err != nil {
+			return fmt.Errorf("colony controller: %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,117 @@
+/*
+Copyright (c) 2024 Sebastian Kroczek <me@xbug.de>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package simulation
+
+import (
+	"bytes"
+	"github.com/gotameme/core/ant"
+	"reflect"
+	"strconv"
+	"testing"
+)
+
+// forager wanders, carries sugar home and counts its waits, so a restored simulation has to restore its state.
+type forager struct {
+	ant.UnimplementedAnt
+	os    ant.AntOs
+	waits int
+}
+
+func (f *forager) Waits() {
+	f.waits++
+	if f.os.GetCurrentLoad() > 0 {
+		f.os.GotToAntHill()
+		return
+	}
+	f.os.Turn((f.waits*47+f.os.GetId()*13)%360 - 180)
+	f.os.GoForward(30)
+}
+
+func (f *forager) SeeSugar(sugar ant.Sugar) {
+	if f.os.GetCurrentLoad() == 0 {
+		f.os.GoToSugar(sugar)
+	}
+}
+
+func (f *forager) ReachedSugar(sugar ant.Sugar) {
+	_ = f.os.TakeSugar(sugar)
+	f.os.GotToAntHill()
+}
+
+func (f *forager) MarshalBinary() ([]byte, error) {
+	return []byte(strconv.Itoa(f.waits)), nil
+}
+
+func (f *forager) UnmarshalBinary(data []byte) (err error) {
+	f.waits, err = strconv.Atoi(string(data))
+	return err
+}
+
+func newDeterministicSimulation() *Simulation {
+	cnf := NewSimulationConfig(
+		WithSeed(42),
+		WithDeterministicUpdates(),
+		WithAntConstructor(func(os ant.AntOs) interface{} {
+			return &forager{os: os}
+		}),
+	)
+	return NewSimulation(800, 600, *cnf)
+}
+
+func update(t *testing.T, s *Simulation, ticks int) {
+	t.Helper()
+	for i := 0; i < ticks; i++ {
+		if err := s.Update(); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestRestoreContinuesLikeAnUninterruptedRun(t *testing.T) {
+	const before, after = 300, 500
+
+	uninterrupted := newDeterministicSimulation()
+	update(t, uninterrupted, before+after)
+
+	saved := newDeterministicSimulation()
+	update(t, saved, before)
+	var buf bytes.Buffer
+	if err := saved.Save(&buf); err != nil {
+		t.Fatal(err)
+	}
+	restored := newDeterministicSimulation()
+	if err := restored.Restore(&buf); err != nil {
+		t.Fatal(err)
+	}
+	update(t, restored, after)
+
+	if got, want := restored.Statistics(), uninterrupted.Statistics(); got != want {
+		t.Errorf("statistics %+v, want %+v", got, want)
+	}
+	if got, want := restored.State(), uninterrupted.State(); !reflect.DeepEqual(got, want) {
+		t.Errorf("state differs from the uninterrupted run:\n%+v\nwant\n%+v", got, want)
+	}
+	if uninterrupted.Statistics().SugarDelivered == 0 && len(uninterrupted.State().Ants) == 0 {
+		t.Error("the run did nothing, the test proves nothing")
+	}
+}
@@ -0,0 +1,203 @@
+/*
+Copyright (c) 2024 Sebastian Kroczek <me@xbug.de>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package simulation
+
+import (
+	"github.com/paulmach/orb"
+)
+
+// State is a plain copy of everything that can be seen in a simulation.
+// It holds no ant code, so it can be stored and shown without the colony that produced it.
+type State struct {
+	Tick  int
+	Hill  HillState
+	Ants  []AntState
+	Sugar []SugarState
+	Marks []MarkState
+}
+
+type HillState struct {
+	X, Y  float64
+	Sugar int
+}
+
+type AntState struct {
+	Id        int
+	Role      string
+	X, Y      float64
+	Direction float64
+	Load      int
+}
+
+type SugarState struct {
+	Id     int
+	X, Y   float64
+	Amount int
+}
+
+type MarkState struct {
+	Id          int
+	X, Y        float64
+	Radius      int
+	Information int
+}
+
+// TickRecorder is called after every tick of the simulation.
+type TickRecorder interface {
+	RecordTick(s *Simulation) error
+}
+
+// State returns a copy of the visible state of the simulation.
+func (s *Simulation) State() State {
+	state := State{
+		Tick: s.tick,
+		Hill: HillState{
+			X:     s.antHill.Position[0],
+			Y:     s.antHill.Position[1],
+			Sugar: s.antHill.CurrentSugar,
+		},
+		Ants:  make([]AntState, 0, len(s.ants)),
+		Sugar: make([]SugarState, 0, len(s.sugar)),
+		Marks: make([]MarkState, 0, len(s.marks)),
+	}
+	for _, a := range s.ants {
+		state.Ants = append(state.Ants, AntState{
+			Id:        a.id,
+			Role:      a.role,
+			X:         a.Position[0],
+			Y:         a.Position[1],
+			Direction: a.CurrentDirection,
+			Load:      a.CurrentSugarLoad,
+		})
+	}
+	for _, sugar := range s.sugar {
+		state.Sugar = append(state.Sugar, SugarState{
+			Id:     sugar.id,
+			X:      sugar.Position[0],
+			Y:      sugar.Position[1],
+			Amount: sugar.CurrentSugar,
+		})
+	}
+	for _, m := range s.marks {
+		state.Marks = append(state.Marks, MarkState{
+			Id:          m.id,
+			X:           m.Position[0],
+			Y:           m.Position[1],
+			Radius:      m.Radius,
+			Information: m.Information,
+		})
+	}
+	return state
+}
+
+// ApplyState makes the simulation show the given state.
+// Ants created here are puppets without ant code, the simulation must not be updated afterward.
+func (s *Simulation) ApplyState(state State) {
+	s.tick = state.Tick
+	if hillPosition := (orb.Point{state.Hill.X, state.Hill.Y}); s.antHill.Position != hillPosition {
+		s.rtree.Delete(s.antHill.Bounds())
+		s.antHill = NewAntHill(s.screenWidth, s.screenHeight, hillPosition)
+		s.rtree.Insert(s.antHill.Bounds())
+	}
+	s.antHill.CurrentSugar = state.Hill.Sugar
+
+	// region ants
+	ants := make(map[int]*AntOS, len(s.ants))
+	for _, a := range s.ants {
+		ants[a.id] = a
+	}
+	s.ants = make([]*AntOS, 0, len(state.Ants))
+	for _, st := range state.Ants {
+		a, ok := ants[st.Id]
+		if ok {
+			delete(ants, st.Id)
+			oldMin, oldMax := a.Bounds()
+			if a.Position != (orb.Point{st.X, st.Y}) {
+				a.AnimatedSprite.Update()
+			}
+			a.Position = orb.Point{st.X, st.Y}
+			newMin, newMax := a.Bounds()
+			s.rtree.Replace(oldMin, oldMax, a, newMin, newMax, a)
+		} else {
+			a = NewAntOS(s, WithAntHill(s.antHill), WithPosition(st.X, st.Y), WithRole(st.Role, s.defaultRoleProperties))
+			a.id = st.Id
+			newMin, newMax := a.Bounds()
+			s.rtree.Insert(newMin, newMax, a)
+		}
+		a.role = st.Role
+		a.CurrentDirection = st.Direction
+		a.CurrentSugarLoad = st.Load
+		s.ants = append(s.ants, a)
+	}
+	for _, a := range ants {
+		vmin, vmax := a.Bounds()
+		s.rtree.Delete(vmin, vmax, a)
+	}
+	// endregion
+
+	// region sugar
+	sugars := make(map[int]*Sugar, len(s.sugar))
+	for _, sugar := range s.sugar {
+		sugars[sugar.id] = sugar
+	}
+	s.sugar = make([]*Sugar, 0, len(state.Sugar))
+	for _, st := range state.Sugar {
+		sugar, ok := sugars[st.Id]
+		if ok {
+			delete(sugars, st.Id)
+		} else {
+			sugar = NewSugar(s, orb.Point{st.X, st.Y})
+			sugar.id = st.Id
+			s.rtree.Insert(sugar.Bounds())
+		}
+		sugar.CurrentSugar = st.Amount
+		s.sugar = append(s.sugar, sugar)
+	}
+	for _, sugar := range sugars {
+		s.rtree.Delete(sugar.Bounds())
+	}
+	// endregion
+
+	// region marks
+	marks := make(map[int]*Marking, len(s.marks))
+	for _, m := range s.marks {
+		marks[m.id] = m
+	}
+	s.marks = make([]*Marking, 0, len(state.Marks))
+	for _, st := range state.Marks {
+		m, ok := marks[st.Id]
+		if ok {
+			delete(marks, st.Id)
+		} else {
+			m = NewMarking(s, orb.Point{st.X, st.Y}, st.Radius, st.Information)
+			m.id = st.Id
+			s.rtree.Insert(m.Bounds())
+		}
+		s.marks = append(s.marks, m)
+	}
+	for _, m := range marks {
+		s.rtree.Delete(m.Bounds())
+		s.markingPool.Put(m)
+	}
+	// endregion
+}
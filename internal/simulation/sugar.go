@@ -23,15 +23,14 @@ package simulation
 
 import (
 	"github.com/gotameme/core/internal/resources"
-	"github.com/gotameme/core/rand"
 	"github.com/hajimehoshi/ebiten/v2"
 	"github.com/paulmach/orb"
-	"log"
 
 	gmath "github.com/gotameme/core/internal/math"
 )
 
 type Sugar struct {
+	id         int
 	simulation *Simulation
 	resources.AnimatedSprite
 	gmath.Rect
@@ -42,8 +41,10 @@ func NewSugar(simulation *Simulation, position orb.Point) *Sugar {
 	sugar := resources.NewSugar(simulation.screenWidth, simulation.screenHeight)
 	sugar.Position = position
 	rect := gmath.NewRect(position[0], position[1], float64(sugar.FrameWidth), float64(sugar.FrameHeight))
-	log.Println(position[0], position[1], float64(sugar.FrameWidth), float64(sugar.FrameHeight), rect)
+	simulation.lastSugarID++
+	simulation.logger.Debug("sugar placed", "sugar", simulation.lastSugarID, "x", position[0], "y", position[1])
 	return &Sugar{
+		id:             simulation.lastSugarID,
 		simulation:     simulation,
 		AnimatedSprite: sugar,
 		Rect:           rect,
@@ -54,11 +55,12 @@ func NewSugar(simulation *Simulation, position orb.Point) *Sugar {
 func NewRandomSugar(simulation *Simulation, border int) *Sugar {
 	var minValue = [2]float64{float64(border), float64(border)}
 	var maxValue = [2]float64{float64(simulation.screenWidth - border), float64(simulation.screenHeight - border)}
-	return NewSugar(simulation, rand.RandomPoint(minValue, maxValue))
+	return NewSugar(simulation, simulation.rand.RandomPoint(minValue, maxValue))
 }
 
 func (s *Sugar) Update() {
 	if s.CurrentSugar <= 0 {
+		s.simulation.emit(Event{Kind: SugarDepleted, SugarID: s.id, X: s.Position[0], Y: s.Position[1]})
 		s.simulation.RemoveSugar(s)
 	}
 }
@@ -78,8 +80,6 @@ func (s *Sugar) Draw(screen *ebiten.Image) {
 	}
 
 	img := s.AnimatedSprite.Draw()
-	// rect := helper.DrawRect(s.Rect, colornames.Beige) // debug
-	// rect.DrawImage(img, nil) // Debug
 	// draw ant at position 100, 100
 	op := &ebiten.DrawImageOptions{}
 	op.GeoM.Translate(s.GetCenteredRotationOffset())
@@ -96,6 +96,10 @@ func (s *Sugar) GetLoad(i int) int {
 	return i
 }
 
+func (s *Sugar) GetId() int {
+	return s.id
+}
+
 func (s *Sugar) GetCurrentSugar() int {
 	return s.CurrentSugar
 }
@@ -0,0 +1,337 @@
+/*
+Copyright (c) 2024 Sebastian Kroczek <me@xbug.de>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+// Package terminal draws a running simulation as text with ANSI colours and reads keys to control it, so a run can
+// be watched over SSH.
+//
+//	a  ant          A  ant carrying sugar
+//	.  o  O  @      sugar, the more the bigger
+//	H  ant hill     :  mark
+//
+// Space pauses and resumes, s steps one tick, S steps ten, + and - double and halve the speed, between
+// control.MinSpeed and control.MaxSpeed, and q stops the run.
+package terminal
+
+import (
+	"bufio"
+	"fmt"
+	"github.com/gotameme/core/internal/control"
+	"github.com/gotameme/core/internal/simulation"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	defaultColumns = 80
+	defaultRows    = 24
+	// frameInterval limits how often the world is drawn
+	frameInterval = 50 * time.Millisecond
+	// sizeInterval limits how often the size of the terminal is checked
+	sizeInterval = time.Second
+	// multiStep is the number of ticks stepped by S
+	multiStep = 10
+)
+
+const (
+	reset       = "\x1b[0m"
+	clearLine   = "\x1b[K"
+	clearScreen = "\x1b[2J"
+	home        = "\x1b[H"
+	hideCursor  = "\x1b[?25l"
+	showCursor  = "\x1b[?25h"
+)
+
+// cell is a character of the grid with its colour.
+type cell struct {
+	char  byte
+	color string
+}
+
+var (
+	empty     = cell{' ', ""}
+	markCell  = cell{':', "\x1b[33m"}
+	hillCell  = cell{'H', "\x1b[1;35m"}
+	antCell   = cell{'a', "\x1b[31m"}
+	loadedAnt = cell{'A', "\x1b[1;33m"}
+)
+
+// Terminal draws the simulation to stdout and turns the keys pressed on stdin into commands.
+type Terminal struct {
+	out      io.Writer
+	commands chan control.Command
+	// restore puts the terminal back into the mode it was in before Open
+	restore func()
+	// interrupts turns ctrl+c into a stop, so the terminal is restored
+	interrupts chan os.Signal
+	// done is closed by Close and stops reading keys and interrupts
+	done chan struct{}
+
+	mu sync.Mutex
+	// sim is the simulation of the last tick, its state is only taken when the world is drawn
+	sim           *simulation.Simulation
+	stale         bool
+	state         simulation.State
+	width, height int
+	paused        bool
+	speed         float64
+	lastDraw      time.Time
+	// columns and rows are the size of the terminal, checked at lastSize
+	columns, rows int
+	lastSize      time.Time
+}
+
+func New() *Terminal {
+	return &Terminal{
+		out:        os.Stdout,
+		commands:   make(chan control.Command, 16),
+		restore:    func() {},
+		interrupts: make(chan os.Signal, 1),
+		done:       make(chan struct{}),
+		speed:      1,
+	}
+}
+
+// Open switches the terminal to read single keys and starts reading them. If the terminal can't be switched, keys
+// take effect after return is pressed.
+func (t *Terminal) Open() error {
+	if restore, err := cbreak(); err == nil {
+		t.restore = restore
+	}
+	fmt.Fprint(t.out, hideCursor+clearScreen)
+	signal.Notify(t.interrupts, os.Interrupt)
+	go func() {
+		for {
+			select {
+			case <-t.interrupts:
+				t.send(control.Command{Kind: control.Stop})
+			case <-t.done:
+				return
+			}
+		}
+	}()
+	go t.readKeys(os.Stdin)
+	return nil
+}
+
+// Close restores the terminal and stops reading interrupts and keys. Reading stdin can't be interrupted, so the key
+// reader ends with the next key or the end of stdin and drops that key.
+func (t *Terminal) Close() error {
+	select {
+	case <-t.done:
+		return nil
+	default:
+		close(t.done)
+	}
+	signal.Stop(t.interrupts)
+	t.restore()
+	t.restore = func() {}
+	_, err := fmt.Fprint(t.out, reset+showCursor+"\n")
+	return err
+}
+
+func (t *Terminal) Commands() <-chan control.Command {
+	return t.commands
+}
+
+// Report redraws the status line if the run was paused, resumed or changed its speed.
+func (t *Terminal) Report(paused bool, speed float64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if paused == t.paused && speed == t.speed {
+		return
+	}
+	t.paused, t.speed = paused, speed
+	t.draw()
+}
+
+func (t *Terminal) RecordTick(sim *simulation.Simulation) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.sim, t.stale = sim, true
+	t.width, t.height = sim.Layout(0, 0)
+	if time.Since(t.lastDraw) >= frameInterval {
+		t.draw()
+	}
+	return nil
+}
+
+func (t *Terminal) readKeys(r io.Reader) {
+	keys := bufio.NewReader(r)
+	for {
+		key, err := keys.ReadByte()
+		if err != nil {
+			return
+		}
+		select {
+		case <-t.done:
+			return
+		default:
+		}
+		t.mu.Lock()
+		paused, speed := t.paused, t.speed
+		t.mu.Unlock()
+		var command control.Command
+		switch key {
+		case ' ', 'p':
+			command.Kind = control.Pause
+			if paused {
+				command.Kind = control.Resume
+			}
+		case 's':
+			command = control.Command{Kind: control.Step, Ticks: 1}
+		case 'S':
+			command = control.Command{Kind: control.Step, Ticks: multiStep}
+		case '+', '=':
+			command = control.Command{Kind: control.SetSpeed, Speed: control.ClampSpeed(speed * 2)}
+		case '-':
+			command = control.Command{Kind: control.SetSpeed, Speed: control.ClampSpeed(speed / 2)}
+		case 'q':
+			command.Kind = control.Stop
+		default:
+			continue
+		}
+		t.send(command)
+	}
+}
+
+func (t *Terminal) send(command control.Command) {
+	select {
+	case t.commands <- command:
+	default:
+		// the run is busy, drop the command
+	}
+}
+
+// draw writes the world and the status line, the caller must hold the lock and run between the ticks.
+func (t *Terminal) draw() {
+	if t.width == 0 {
+		return
+	}
+	if t.stale {
+		t.state, t.stale = t.sim.State(), false
+	}
+	t.lastDraw = time.Now()
+	var b strings.Builder
+	if time.Since(t.lastSize) >= sizeInterval {
+		if columns, rows := size(); columns != t.columns || rows != t.rows {
+			// the old frame doesn't cover the screen anymore
+			t.columns, t.rows = columns, rows
+			b.WriteString(clearScreen)
+		}
+		t.lastSize = time.Now()
+	}
+	b.WriteString(home)
+	for _, line := range Render(t.state, t.width, t.height, t.columns, t.rows-1) {
+		b.WriteString(line)
+		b.WriteString(clearLine + "\n")
+	}
+	b.WriteString(t.status(t.columns))
+	b.WriteString(clearLine)
+	_, _ = io.WriteString(t.out, b.String())
+}
+
+func (t *Terminal) status(columns int) string {
+	status := fmt.Sprintf("Tick %d  Sugar %d  Ants %d  Speed %gx", t.state.Tick, t.state.Hill.Sugar,
+		len(t.state.Ants), t.speed)
+	if t.paused {
+		status += "  PAUSED"
+	}
+	status += "  [space] pause [s/S] step [+/-] speed [q] quit"
+	if len(status) > columns {
+		status = status[:columns]
+	}
+	return "\x1b[7m" + status + reset
+}
+
+// Render maps the world of the given size to a grid of columns by rows characters, one line per row.
+func Render(state simulation.State, width, height, columns, rows int) []string {
+	grid := make([][]cell, rows)
+	for i := range grid {
+		grid[i] = make([]cell, columns)
+		for j := range grid[i] {
+			grid[i][j] = empty
+		}
+	}
+	cellWidth, cellHeight := float64(width)/float64(columns), float64(height)/float64(rows)
+	set := func(x, y float64, c cell) {
+		col, row := int(x/cellWidth), int(y/cellHeight)
+		if col >= 0 && row >= 0 && col < columns && row < rows {
+			grid[row][col] = c
+		}
+	}
+	// later layers cover earlier ones
+	for _, m := range state.Marks {
+		r := float64(m.Radius)
+		for y := m.Y - r; y <= m.Y+r; y += cellHeight {
+			for x := m.X - r; x <= m.X+r; x += cellWidth {
+				if (x-m.X)*(x-m.X)+(y-m.Y)*(y-m.Y) <= r*r {
+					set(x, y, markCell)
+				}
+			}
+		}
+	}
+	set(state.Hill.X, state.Hill.Y, hillCell)
+	for _, s := range state.Sugar {
+		set(s.X, s.Y, sugarCell(s.Amount))
+	}
+	for _, a := range state.Ants {
+		if a.Load > 0 {
+			set(a.X, a.Y, loadedAnt)
+		} else {
+			set(a.X, a.Y, antCell)
+		}
+	}
+
+	lines := make([]string, rows)
+	for i, row := range grid {
+		var b strings.Builder
+		color := ""
+		for _, c := range row {
+			if c.color != color {
+				b.WriteString(reset + c.color)
+				color = c.color
+			}
+			b.WriteByte(c.char)
+		}
+		b.WriteString(reset)
+		lines[i] = b.String()
+	}
+	return lines
+}
+
+// sugarCell sizes a pile by the sugar that is left, a new pile has about 1000.
+func sugarCell(amount int) cell {
+	switch {
+	case amount < 100:
+		return cell{'.', "\x1b[1;37m"}
+	case amount < 400:
+		return cell{'o', "\x1b[1;37m"}
+	case amount < 700:
+		return cell{'O', "\x1b[1;37m"}
+	default:
+		return cell{'@', "\x1b[1;37m"}
+	}
+}
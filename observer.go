@@ -0,0 +1,58 @@
+/*
+Copyright (c) 2024 Sebastian Kroczek <me@xbug.de>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package core
+
+import "github.com/gotameme/core/internal/simulation"
+
+type (
+	// Event is something that happened in a simulation, only the fields that matter for its kind are set.
+	Event = simulation.Event
+	// EventKind is the kind of an Event.
+	EventKind = simulation.EventKind
+	// Observer receives the events of a simulation.
+	Observer = simulation.Observer
+	// ObserverFunc is a function that is an Observer.
+	ObserverFunc = simulation.ObserverFunc
+)
+
+const (
+	TickStarted    = simulation.TickStarted
+	TickFinished   = simulation.TickFinished
+	AntSpawned     = simulation.AntSpawned
+	AntDied        = simulation.AntDied
+	SugarSeen      = simulation.SugarSeen
+	SugarTaken     = simulation.SugarTaken
+	SugarDelivered = simulation.SugarDelivered
+	SugarDepleted  = simulation.SugarDepleted
+	MarkSet        = simulation.MarkSet
+	MarkExpired    = simulation.MarkExpired
+)
+
+// WithObserver registers an observer for the events of the simulation.
+// The events are delivered synchronously at the end of every tick, between TickStarted and TickFinished,
+// in an order that does not depend on how the ants were scheduled. Observers must not block.
+func WithObserver(observer Observer) Option {
+	return func(cnf *Configuration) {
+		simulation.WithObserver(observer)(&cnf.GameConfiguration.SimulationConfig)
+	}
+}
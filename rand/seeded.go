@@ -0,0 +1,102 @@
+/*
+Copyright (c) 2024 Sebastian Kroczek <me@xbug.de>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package rand
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	mrand "math/rand/v2"
+
+	"github.com/paulmach/orb"
+)
+
+// Rand is a seeded source of random numbers, the same seed gives the same numbers.
+type Rand struct {
+	seed uint64
+	pcg  *mrand.PCG
+	rand *mrand.Rand
+}
+
+func New(seed uint64) *Rand {
+	pcg := mrand.NewPCG(seed, seed)
+	return &Rand{
+		seed: seed,
+		pcg:  pcg,
+		rand: mrand.New(pcg),
+	}
+}
+
+// NewSeed returns a seed from the cryptographic random source.
+func NewSeed() uint64 {
+	var b [8]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		panic(err)
+	}
+	return binary.LittleEndian.Uint64(b[:])
+}
+
+func (r *Rand) Seed() uint64 {
+	return r.seed
+}
+
+func (r *Rand) Intn(n int) int {
+	return r.rand.IntN(n)
+}
+
+func (r *Rand) IntMinMax(min, max int) int {
+	return r.Intn(max-min) + min
+}
+
+func (r *Rand) Float64() float64 {
+	return r.rand.Float64()
+}
+
+func (r *Rand) Float64MinMax(min, max float64) float64 {
+	return r.Float64()*(max-min) + min
+}
+
+func (r *Rand) RandomPoint(min, max [2]float64) orb.Point {
+	return orb.Point{r.Float64MinMax(min[0], max[0]), r.Float64MinMax(min[1], max[1])}
+}
+
+// MarshalBinary returns the seed and the current state, so a restored Rand continues with the same numbers.
+func (r *Rand) MarshalBinary() ([]byte, error) {
+	state, err := r.pcg.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	return binary.LittleEndian.AppendUint64(state, r.seed), nil
+}
+
+func (r *Rand) UnmarshalBinary(data []byte) error {
+	if len(data) < 8 {
+		return errors.New("rand: invalid state")
+	}
+	state, seed := data[:len(data)-8], binary.LittleEndian.Uint64(data[len(data)-8:])
+	if err := r.pcg.UnmarshalBinary(state); err != nil {
+		return err
+	}
+	r.seed = seed
+	return nil
+}
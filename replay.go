@@ -0,0 +1,48 @@
+/*
+Copyright (c) 2024 Sebastian Kroczek <me@xbug.de>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package core
+
+import (
+	"errors"
+	"github.com/gotameme/core/internal"
+	"github.com/gotameme/core/internal/replay"
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// Replay plays back a replay file recorded with WithReplayRecorder, the ant code of the recording is not needed.
+// Space pauses, left and right step one tick, up and down change the speed,
+// home and end jump to the first and last tick and a click on the progress bar at the bottom seeks.
+func Replay(path string) error {
+	r, err := replay.Load(path)
+	if err != nil {
+		return err
+	}
+	ebiten.SetWindowSize(r.ScreenWidth, r.ScreenHeight)
+	ebiten.SetWindowTitle("Go! Tame! Me! - Replay")
+	ebiten.SetTPS(60)
+	err = ebiten.RunGame(internal.NewReplayGame(r))
+	if errors.Is(err, internal.Quit) {
+		err = nil
+	}
+	return err
+}
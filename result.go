@@ -0,0 +1,87 @@
+/*
+Copyright (c) 2024 Sebastian Kroczek <me@xbug.de>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package core
+
+import (
+	"github.com/gotameme/core/internal/metrics"
+	"github.com/gotameme/core/internal/simulation"
+)
+
+type (
+	// Fault records a panic inside a callback of an ant or a colony controller.
+	Fault = simulation.Fault
+	// FaultPolicy defines how the simulation handles an ant whose callback panicked.
+	FaultPolicy = simulation.FaultPolicy
+	// BudgetPolicy defines how the simulation handles a colony whose callbacks exceed their time budget.
+	BudgetPolicy = simulation.BudgetPolicy
+	// TimingReport sums up the time spent in the callbacks of a colony.
+	TimingReport = simulation.TimingReport
+	// CallbackTiming sums up the time spent in one kind of callback.
+	CallbackTiming = simulation.CallbackTiming
+	// Offence records a callback or a tick that took longer than its budget.
+	Offence = simulation.Offence
+	// Statistics sum up the outcome of a run.
+	Statistics = simulation.Statistics
+	// MetricsFormat is the file format of WithMetrics.
+	MetricsFormat = metrics.Format
+	// MetricsSample is a row written by WithMetrics.
+	MetricsSample = metrics.Sample
+	// HeatmapSplit defines the layers of the heatmap of WithHeatmap.
+	HeatmapSplit = simulation.HeatmapSplit
+)
+
+const (
+	AbortSimulation = simulation.AbortSimulation
+	DisableAnt      = simulation.DisableAnt
+	RespawnAnt      = simulation.RespawnAnt
+
+	SkipCallbacks    = simulation.SkipCallbacks
+	PenaliseOffender = simulation.PenaliseOffender
+	DisqualifyColony = simulation.DisqualifyColony
+
+	MetricsCSV       = metrics.CSV
+	MetricsJSONLines = metrics.JSONLines
+
+	HeatmapTotal  = simulation.HeatmapTotal
+	HeatmapByRole = simulation.HeatmapByRole
+	HeatmapByLoad = simulation.HeatmapByLoad
+)
+
+// Result summarizes a finished run.
+type Result struct {
+	Ticks   int
+	Seed    uint64
+	Faults  []*Fault
+	Timings []TimingReport
+	simulation.Statistics
+}
+
+func newResult(s *simulation.Simulation) *Result {
+	return &Result{
+		Ticks:      s.Tick(),
+		Seed:       s.Seed(),
+		Faults:     s.Faults(),
+		Timings:    s.TimingReports(),
+		Statistics: s.Statistics(),
+	}
+}
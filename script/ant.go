@@ -0,0 +1,278 @@
+/*
+Copyright (c) 2024 Sebastian Kroczek <me@xbug.de>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package script
+
+import (
+	"fmt"
+	"github.com/gotameme/core/ant"
+	"go.starlark.net/starlark"
+	"go.starlark.net/starlarkstruct"
+	"sort"
+	"sync"
+)
+
+// scriptAnt forwards the callbacks of an ant to the script.
+type scriptAnt struct {
+	script *Script
+	os     ant.AntOs
+	state  *starlark.Dict
+
+	// callbacks of an ant may run concurrently, but share the state of the ant
+	mu sync.Mutex
+}
+
+func (a *scriptAnt) Waits() {
+	a.call("waits")
+}
+
+func (a *scriptAnt) SeeSugar(sugar ant.Sugar) {
+	a.call("see_sugar", sugarValue{sugar})
+}
+
+func (a *scriptAnt) ReachedSugar(sugar ant.Sugar) {
+	a.call("reached_sugar", sugarValue{sugar})
+}
+
+func (a *scriptAnt) SeeMark(mark ant.Mark) {
+	a.call("see_mark", starlark.MakeInt(mark.GetInformation()))
+}
+
+func (a *scriptAnt) Hear(from ant.FriendInfo, msg int) {
+	friend := starlarkstruct.FromStringDict(starlarkstruct.Default, starlark.StringDict{
+		"id":        starlark.MakeInt(from.Id),
+		"role":      starlark.String(from.Role),
+		"load":      starlark.MakeInt(from.CurrentLoad),
+		"direction": starlark.MakeInt(from.Direction),
+		"distance":  starlark.MakeInt(from.Distance),
+	})
+	a.call("hear", friend, starlark.MakeInt(msg))
+}
+
+func (a *scriptAnt) Tick() {
+	a.call("tick")
+}
+
+func (a *scriptAnt) call(name string, args ...starlark.Value) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if err := a.script.call(a.os, name, append(starlark.Tuple{antValue{a}}, args...)...); err != nil {
+		panic(err)
+	}
+}
+
+// sugarValue is the Starlark value of an ant.Sugar.
+type sugarValue struct {
+	ant.Sugar
+}
+
+func (s sugarValue) String() string {
+	return fmt.Sprintf("sugar(id=%d, amount=%d)", s.GetId(), s.GetCurrentSugar())
+}
+
+func (s sugarValue) Type() string          { return "sugar" }
+func (s sugarValue) Freeze()               {}
+func (s sugarValue) Truth() starlark.Bool  { return starlark.True }
+func (s sugarValue) Hash() (uint32, error) { return uint32(s.GetId()), nil }
+
+func (s sugarValue) Attr(name string) (starlark.Value, error) {
+	switch name {
+	case "id":
+		return starlark.MakeInt(s.GetId()), nil
+	case "amount":
+		return starlark.MakeInt(s.GetCurrentSugar()), nil
+	}
+	return nil, nil
+}
+
+func (s sugarValue) AttrNames() []string {
+	return []string{"amount", "id"}
+}
+
+// antValue is the Starlark value of an ant, it mirrors ant.AntOs.
+type antValue struct {
+	a *scriptAnt
+}
+
+func (v antValue) String() string {
+	return fmt.Sprintf("ant(id=%d)", v.a.os.GetId())
+}
+
+func (v antValue) Type() string          { return "ant" }
+func (v antValue) Freeze()               {}
+func (v antValue) Truth() starlark.Bool  { return starlark.True }
+func (v antValue) Hash() (uint32, error) { return uint32(v.a.os.GetId()), nil }
+
+func (v antValue) Attr(name string) (starlark.Value, error) {
+	os := v.a.os
+	switch name {
+	case "id":
+		return starlark.MakeInt(os.GetId()), nil
+	case "role":
+		return starlark.String(os.GetRole()), nil
+	case "load":
+		return starlark.MakeInt(os.GetCurrentLoad()), nil
+	case "order":
+		return starlark.MakeInt(os.GetOrder()), nil
+	case "state":
+		return v.a.state, nil
+	}
+	if method, ok := antMethods[name]; ok {
+		return method.BindReceiver(v), nil
+	}
+	return nil, nil
+}
+
+func (v antValue) AttrNames() []string {
+	names := []string{"id", "load", "order", "role", "state"}
+	for name := range antMethods {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func unpackSugar(b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (ant.Sugar, error) {
+	var sugar sugarValue
+	if err := starlark.UnpackPositionalArgs(b.Name(), args, kwargs, 1, &sugar); err != nil {
+		return nil, err
+	}
+	return sugar.Sugar, nil
+}
+
+func unpackInts(b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple, ints ...*int) error {
+	vars := make([]any, len(ints))
+	for i := range ints {
+		vars[i] = ints[i]
+	}
+	return starlark.UnpackPositionalArgs(b.Name(), args, kwargs, len(ints), vars...)
+}
+
+func antOs(b *starlark.Builtin) ant.AntOs {
+	return b.Receiver().(antValue).a.os
+}
+
+var antMethods = map[string]*starlark.Builtin{
+	"go_forwards": starlark.NewBuiltin("go_forwards", func(_ *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+		if err := starlark.UnpackPositionalArgs(b.Name(), args, kwargs, 0); err != nil {
+			return nil, err
+		}
+		antOs(b).GoForwards()
+		return starlark.None, nil
+	}),
+	"go_forward": starlark.NewBuiltin("go_forward", func(_ *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+		var steps int
+		if err := unpackInts(b, args, kwargs, &steps); err != nil {
+			return nil, err
+		}
+		antOs(b).GoForward(steps)
+		return starlark.None, nil
+	}),
+	"turn": starlark.NewBuiltin("turn", func(_ *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+		var direction int
+		if err := unpackInts(b, args, kwargs, &direction); err != nil {
+			return nil, err
+		}
+		antOs(b).Turn(direction)
+		return starlark.None, nil
+	}),
+	"go_to_sugar": starlark.NewBuiltin("go_to_sugar", func(_ *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+		sugar, err := unpackSugar(b, args, kwargs)
+		if err != nil {
+			return nil, err
+		}
+		antOs(b).GoToSugar(sugar)
+		return starlark.None, nil
+	}),
+	"take_sugar": starlark.NewBuiltin("take_sugar", func(_ *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+		sugar, err := unpackSugar(b, args, kwargs)
+		if err != nil {
+			return nil, err
+		}
+		return starlark.Bool(antOs(b).TakeSugar(sugar) == nil), nil
+	}),
+	"direction_to_sugar": starlark.NewBuiltin("direction_to_sugar", func(_ *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+		sugar, err := unpackSugar(b, args, kwargs)
+		if err != nil {
+			return nil, err
+		}
+		return starlark.MakeInt(antOs(b).GetDirectionToSugar(sugar)), nil
+	}),
+	"go_to_ant_hill": starlark.NewBuiltin("go_to_ant_hill", func(_ *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+		if err := starlark.UnpackPositionalArgs(b.Name(), args, kwargs, 0); err != nil {
+			return nil, err
+		}
+		antOs(b).GotToAntHill()
+		return starlark.None, nil
+	}),
+	"set_mark": starlark.NewBuiltin("set_mark", func(_ *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+		var radius, information int
+		if err := unpackInts(b, args, kwargs, &radius, &information); err != nil {
+			return nil, err
+		}
+		antOs(b).SetMark(radius, information)
+		return starlark.None, nil
+	}),
+	"say": starlark.NewBuiltin("say", func(_ *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+		var msg int
+		if err := unpackInts(b, args, kwargs, &msg); err != nil {
+			return nil, err
+		}
+		return starlark.Bool(antOs(b).Say(msg) == nil), nil
+	}),
+	"colony_get": starlark.NewBuiltin("colony_get", func(_ *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+		var key string
+		var defaultValue starlark.Value = starlark.None
+		if err := starlark.UnpackArgs(b.Name(), args, kwargs, "key", &key, "default?", &defaultValue); err != nil {
+			return nil, err
+		}
+		if value, ok := antOs(b).Colony().Get(key); ok {
+			if value, ok := value.(starlark.Value); ok {
+				return value, nil
+			}
+		}
+		return defaultValue, nil
+	}),
+	"colony_set": starlark.NewBuiltin("colony_set", func(_ *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+		var key string
+		var value starlark.Value
+		if err := starlark.UnpackArgs(b.Name(), args, kwargs, "key", &key, "value", &value); err != nil {
+			return nil, err
+		}
+		// values in the colony memory are shared between ants, so they must not change anymore
+		value.Freeze()
+		return starlark.Bool(antOs(b).Colony().Set(key, value) == nil), nil
+	}),
+	"log": starlark.NewBuiltin("log", func(_ *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+		var msg string
+		if err := starlark.UnpackPositionalArgs(b.Name(), args, nil, 1, &msg); err != nil {
+			return nil, err
+		}
+		// the keyword arguments are the attributes of the entry
+		attrs := make([]any, 0, 2*len(kwargs))
+		for _, kwarg := range kwargs {
+			attrs = append(attrs, string(kwarg[0].(starlark.String)), kwarg[1].String())
+		}
+		antOs(b).Log(msg, attrs...)
+		return starlark.None, nil
+	}),
+}
@@ -0,0 +1,234 @@
+/*
+Copyright (c) 2024 Sebastian Kroczek <me@xbug.de>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+// Package script runs ants written in Starlark, a small dialect of Python that is embedded in the engine.
+//
+// A script defines the callbacks of ant.Ant as functions, all of them are optional:
+//
+//	def waits(ant): ...
+//	def see_sugar(ant, sugar): ...
+//	def reached_sugar(ant, sugar): ...
+//	def see_mark(ant, information): ...
+//	def hear(ant, friend, msg): ...
+//	def tick(ant): ...
+//
+// The ant argument mirrors ant.AntOs. It has the attributes id, role, load and order, the methods go_forwards(),
+// go_forward(steps), turn(direction), go_to_sugar(sugar), take_sugar(sugar), go_to_ant_hill(),
+// set_mark(radius, information), say(msg), log(msg, **attrs), direction_to_sugar(sugar), colony_get(key, default=None)
+// and colony_set(key, value), and a dict named state to remember things between callbacks. print logs like log.
+// Sugar has the attributes id and amount, a friend has the attributes id, role, load, direction and distance.
+//
+// Module-level values are frozen after the script is loaded, because the callbacks of many ants run at the same time.
+// An ant keeps its own values in ant.state, values shared by the colony go through colony_get and colony_set.
+//
+// While loops and recursion are allowed, but every callback runs with a step limit. The script is reloaded when the file changes, the ants keep their state.
+// If the changed script fails to load, the ants keep running the previous version.
+package script
+
+import (
+	"fmt"
+	"github.com/gotameme/core/ant"
+	"go.starlark.net/starlark"
+	"go.starlark.net/syntax"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+)
+
+const (
+	defaultMaxSteps       = 100_000
+	defaultReloadInterval = time.Second
+)
+
+// fileOptions allows while loops and recursion, the step limit keeps them from running forever.
+var fileOptions = &syntax.FileOptions{
+	Set:       true,
+	While:     true,
+	Recursion: true,
+}
+
+// callbacks lists the functions a script can define.
+var callbacks = []string{"waits", "see_sugar", "reached_sugar", "see_mark", "hear", "tick"}
+
+type Option func(*Script)
+
+// WithMaxSteps limits the number of steps a single callback may take.
+func WithMaxSteps(steps uint64) Option {
+	return func(s *Script) {
+		s.maxSteps = steps
+	}
+}
+
+// WithLogger defines the logger for the messages of the script itself, like a failed reload.
+func WithLogger(logger *slog.Logger) Option {
+	return func(s *Script) {
+		s.logger = logger
+	}
+}
+
+// WithReloadInterval defines how often the script is checked for changes, 0 disables reloading.
+func WithReloadInterval(interval time.Duration) Option {
+	return func(s *Script) {
+		s.reloadInterval = interval
+	}
+}
+
+// Script is a Starlark script that drives the ants of a colony.
+type Script struct {
+	path           string
+	maxSteps       uint64
+	reloadInterval time.Duration
+	logger         *slog.Logger
+
+	mu        sync.RWMutex
+	functions map[string]starlark.Callable
+	modTime   time.Time
+	lastCheck time.Time
+}
+
+// Load loads the script at the given path.
+func Load(path string, options ...Option) (*Script, error) {
+	s := &Script{
+		path:           path,
+		maxSteps:       defaultMaxSteps,
+		reloadInterval: defaultReloadInterval,
+		logger:         slog.Default(),
+	}
+	for _, option := range options {
+		option(s)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if err = s.load(info.ModTime()); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// AntConstructor returns a constructor for ants driven by the script.
+// The callbacks of these ants panic if the script fails or exceeds its step limit, so the fault policy of the
+// simulation applies.
+func (s *Script) AntConstructor() ant.AntConstructor {
+	return func(antOs ant.AntOs) interface{} {
+		return &scriptAnt{script: s, os: antOs, state: starlark.NewDict(0)}
+	}
+}
+
+func (s *Script) load(modTime time.Time) error {
+	src, err := os.ReadFile(s.path)
+	if err != nil {
+		return err
+	}
+	thread := s.newThread("load", func(msg string) {
+		s.logger.Info(msg, "script", s.path)
+	})
+	globals, err := starlark.ExecFileOptions(fileOptions, thread, s.path, src, nil)
+	if err != nil {
+		return err
+	}
+	// the callbacks of many ants run at the same time, so the module must not change anymore
+	globals.Freeze()
+	functions := make(map[string]starlark.Callable)
+	for _, name := range callbacks {
+		if fn, ok := globals[name].(starlark.Callable); ok {
+			functions[name] = fn
+		}
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.functions = functions
+	s.modTime = modTime
+	return nil
+}
+
+// reload loads the script again if the file changed since it was loaded.
+func (s *Script) reload() {
+	if s.reloadInterval <= 0 {
+		return
+	}
+	// most callbacks come before the interval is over, they only need the read lock
+	s.mu.RLock()
+	due := time.Since(s.lastCheck) >= s.reloadInterval
+	s.mu.RUnlock()
+	if !due {
+		return
+	}
+	s.mu.Lock()
+	if time.Since(s.lastCheck) < s.reloadInterval {
+		// another callback checked in the meantime
+		s.mu.Unlock()
+		return
+	}
+	s.lastCheck = time.Now()
+	modTime := s.modTime
+	s.mu.Unlock()
+
+	info, err := os.Stat(s.path)
+	if err != nil || !info.ModTime().After(modTime) {
+		return
+	}
+	if err = s.load(info.ModTime()); err != nil {
+		s.logger.Warn("script keeps previous version", "script", s.path, "error", err)
+		// don't try again until the file changes again
+		s.mu.Lock()
+		s.modTime = info.ModTime()
+		s.mu.Unlock()
+		return
+	}
+	s.logger.Info("script reloaded", "script", s.path)
+}
+
+func (s *Script) function(name string) starlark.Callable {
+	s.reload()
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.functions[name]
+}
+
+func (s *Script) newThread(name string, print func(msg string)) *starlark.Thread {
+	thread := &starlark.Thread{
+		Name: name,
+		Print: func(_ *starlark.Thread, msg string) {
+			print(msg)
+		},
+	}
+	thread.SetMaxExecutionSteps(s.maxSteps)
+	return thread
+}
+
+// call calls the function with the given name, print of the function logs with the ant.
+func (s *Script) call(antOs ant.AntOs, name string, args ...starlark.Value) error {
+	fn := s.function(name)
+	if fn == nil {
+		return nil
+	}
+	thread := s.newThread(name, func(msg string) {
+		antOs.Log(msg)
+	})
+	if _, err := starlark.Call(thread, fn, args, nil); err != nil {
+		return fmt.Errorf("script %s: %w", s.path, err)
+	}
+	return nil
+}
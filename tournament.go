@@ -0,0 +1,467 @@
+/*
+Copyright (c) 2024 Sebastian Kroczek <me@xbug.de>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package core
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/gotameme/core/ant"
+	"io"
+	"math"
+	"runtime"
+	"sort"
+	"strconv"
+	"sync"
+	"text/tabwriter"
+)
+
+const (
+	initialRating  = 1500
+	defaultKFactor = 32
+)
+
+// Pairing defines who plays against whom in a tournament.
+type Pairing int
+
+const (
+	// RoundRobin pairs every entrant with every other entrant once.
+	RoundRobin Pairing = iota
+	// Swiss pairs entrants with similar points each round, without rematches where possible.
+	Swiss
+)
+
+// Entrant is a colony taking part in a tournament.
+type Entrant struct {
+	Name string
+	// Options return the options that make up the colony, they are called once per game.
+	Options func() ([]Option, error)
+}
+
+// ColonyEntrant is an entrant whose ants are Go code.
+func ColonyEntrant(name string, antConstructor ant.AntConstructor, options ...Option) Entrant {
+	return Entrant{
+		Name: name,
+		Options: func() ([]Option, error) {
+			return append([]Option{WithAntConstructor(antConstructor)}, options...), nil
+		},
+	}
+}
+
+// BotEntrant is an entrant whose ants are driven by an external process, see WithExternalAnts.
+func BotEntrant(name, command string, args ...string) Entrant {
+	return Entrant{
+		Name: name,
+		Options: func() ([]Option, error) {
+			return []Option{WithExternalAnts(command, args...)}, nil
+		},
+	}
+}
+
+// Scenario is a set of options every entrant plays, e.g. a layout or an amount of sugar.
+type Scenario struct {
+	Name    string
+	Options []Option
+}
+
+// TournamentSpec describes a tournament.
+//
+// Colonies do not share a field yet, so a game between two entrants means both play the same scenario with the same
+// seed on their own, and the one that delivers more sugar wins. A game that ends with an error is lost.
+// Every pairing plays one game per scenario and seed.
+type TournamentSpec struct {
+	Entrants []Entrant
+	// Scenarios default to a single scenario with the default options
+	Scenarios []Scenario
+	Seeds     []uint64
+	Ticks     int
+	Pairing   Pairing
+	// Rounds of a Swiss tournament, by default enough to find a winner
+	Rounds int
+	// Workers is the number of games that run concurrently, by default one per CPU
+	Workers int
+	// KFactor of the Elo ratings, 32 by default
+	KFactor float64
+}
+
+// MatchResult is the result of a single game between two entrants.
+type MatchResult struct {
+	Round    int     `json:"round"`
+	Scenario string  `json:"scenario"`
+	Seed     uint64  `json:"seed"`
+	A        string  `json:"a"`
+	B        string  `json:"b"`
+	ScoreA   int     `json:"scoreA"`
+	ScoreB   int     `json:"scoreB"`
+	ErrorA   string  `json:"errorA,omitempty"`
+	ErrorB   string  `json:"errorB,omitempty"`
+	Result   float64 `json:"result"` // 1 if A won, 0.5 for a draw and 0 if B won
+}
+
+// Standing is the place of an entrant after the tournament.
+// A pairing is a game per scenario and seed, Played and Byes count these games.
+type Standing struct {
+	Rank           int     `json:"rank"`
+	Name           string  `json:"name"`
+	Played         int     `json:"played"`
+	Wins           int     `json:"wins"`
+	Draws          int     `json:"draws"`
+	Losses         int     `json:"losses"`
+	Byes           int     `json:"byes"`
+	Points         float64 `json:"points"`
+	Rating         float64 `json:"rating"`
+	SugarDelivered int     `json:"sugarDelivered"`
+}
+
+type TournamentResult struct {
+	Standings []Standing    `json:"standings"`
+	Matches   []MatchResult `json:"matches"`
+}
+
+type game struct {
+	entrant, scenario, seed int
+}
+
+// RunTournament plays the tournament headless and ranks the entrants by points, then rating.
+func RunTournament(spec TournamentSpec) (*TournamentResult, error) {
+	if len(spec.Entrants) < 2 {
+		return nil, errors.New("tournament needs at least two entrants")
+	}
+	if len(spec.Seeds) == 0 {
+		return nil, errors.New("tournament needs at least one seed")
+	}
+	if spec.Ticks <= 0 {
+		return nil, errors.New("tournament needs a positive number of ticks")
+	}
+	names := make(map[string]bool)
+	for _, e := range spec.Entrants {
+		if names[e.Name] {
+			return nil, fmt.Errorf("entrant %s takes part twice", e.Name)
+		}
+		names[e.Name] = true
+	}
+	if len(spec.Scenarios) == 0 {
+		spec.Scenarios = []Scenario{{Name: "default"}}
+	}
+	if spec.KFactor <= 0 {
+		spec.KFactor = defaultKFactor
+	}
+
+	// the result of a game does not depend on the opponent, so every entrant plays every scenario and seed once
+	games := playGames(spec)
+
+	t := newTable(spec.Entrants)
+	robin := roundRobin(len(spec.Entrants))
+	for round := 0; round < rounds(spec); round++ {
+		// pairs are a slice of two entrants, a pair whose second entrant is -1 is a bye
+		pairs := robin[round%len(robin)]
+		if spec.Pairing == Swiss {
+			// Swiss rounds depend on the results of the rounds before
+			pairs = swissRound(t)
+		}
+		for _, pair := range pairs {
+			if pair[1] < 0 {
+				t.bye(pair[0], len(spec.Scenarios)*len(spec.Seeds))
+				continue
+			}
+			for s, scenario := range spec.Scenarios {
+				for k, seed := range spec.Seeds {
+					a := games[game{pair[0], s, k}]
+					b := games[game{pair[1], s, k}]
+					match := MatchResult{
+						Round:    round + 1,
+						Scenario: scenario.Name,
+						Seed:     seed,
+						A:        spec.Entrants[pair[0]].Name,
+						B:        spec.Entrants[pair[1]].Name,
+						ScoreA:   a.SugarDelivered,
+						ScoreB:   b.SugarDelivered,
+						ErrorA:   a.Error,
+						ErrorB:   b.Error,
+						Result:   compare(a, b),
+					}
+					t.record(pair[0], pair[1], match.Result, spec.KFactor)
+					t.result.Matches = append(t.result.Matches, match)
+				}
+			}
+		}
+	}
+	for i := range spec.Entrants {
+		for s := range spec.Scenarios {
+			for k := range spec.Seeds {
+				t.result.Standings[i].SugarDelivered += games[game{i, s, k}].SugarDelivered
+			}
+		}
+	}
+	t.rank()
+	return &t.result, nil
+}
+
+func playGames(spec TournamentSpec) map[game]RunStats {
+	workers := spec.Workers
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+	games := make(map[game]RunStats)
+	mu := sync.Mutex{}
+	jobs := make(chan game)
+	wg := sync.WaitGroup{}
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for g := range jobs {
+				entrant := spec.Entrants[g.entrant]
+				seed := spec.Seeds[g.seed]
+				var stats RunStats
+				if options, err := entrant.Options(); err != nil {
+					stats = RunStats{Seed: seed, Error: err.Error()}
+				} else {
+					options = append(append([]Option(nil), spec.Scenarios[g.scenario].Options...), options...)
+					options = append(options, WithColonyName(entrant.Name), WithDeterministicUpdates())
+					stats = runHeadless(options, spec.Ticks, seed)
+				}
+				mu.Lock()
+				games[g] = stats
+				mu.Unlock()
+			}
+		}()
+	}
+	for e := range spec.Entrants {
+		for s := range spec.Scenarios {
+			for k := range spec.Seeds {
+				jobs <- game{e, s, k}
+			}
+		}
+	}
+	close(jobs)
+	wg.Wait()
+	return games
+}
+
+// compare returns 1 if a won, 0.5 for a draw and 0 if b won.
+func compare(a, b RunStats) float64 {
+	scoreA, scoreB := a.SugarDelivered, b.SugarDelivered
+	if a.Error != "" {
+		scoreA = -1
+	}
+	if b.Error != "" {
+		scoreB = -1
+	}
+	switch {
+	case scoreA > scoreB:
+		return 1
+	case scoreA < scoreB:
+		return 0
+	}
+	return 0.5
+}
+
+// region Table
+
+type table struct {
+	result TournamentResult
+	played map[[2]int]bool
+}
+
+func newTable(entrants []Entrant) *table {
+	t := &table{played: make(map[[2]int]bool)}
+	for _, e := range entrants {
+		t.result.Standings = append(t.result.Standings, Standing{Name: e.Name, Rating: initialRating})
+	}
+	return t
+}
+
+// bye wins the games of a pairing without an opponent.
+func (t *table) bye(i int, games int) {
+	t.result.Standings[i].Byes += games
+	t.result.Standings[i].Points += float64(games)
+}
+
+func (t *table) record(a, b int, result float64, k float64) {
+	sa, sb := &t.result.Standings[a], &t.result.Standings[b]
+	sa.Played++
+	sb.Played++
+	sa.Points += result
+	sb.Points += 1 - result
+	switch result {
+	case 1:
+		sa.Wins++
+		sb.Losses++
+	case 0:
+		sa.Losses++
+		sb.Wins++
+	default:
+		sa.Draws++
+		sb.Draws++
+	}
+	expected := 1 / (1 + math.Pow(10, (sb.Rating-sa.Rating)/400))
+	sa.Rating += k * (result - expected)
+	sb.Rating -= k * (result - expected)
+	t.played[[2]int{a, b}] = true
+	t.played[[2]int{b, a}] = true
+}
+
+// order returns the indices of the entrants, best first.
+func (t *table) order() []int {
+	order := make([]int, len(t.result.Standings))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		a, b := t.result.Standings[order[i]], t.result.Standings[order[j]]
+		if a.Points != b.Points {
+			return a.Points > b.Points
+		}
+		return a.Rating > b.Rating
+	})
+	return order
+}
+
+func (t *table) rank() {
+	standings := make([]Standing, 0, len(t.result.Standings))
+	for rank, i := range t.order() {
+		s := t.result.Standings[i]
+		s.Rank = rank + 1
+		standings = append(standings, s)
+	}
+	t.result.Standings = standings
+}
+
+// endregion
+
+// region Pairings
+
+// rounds returns the number of rounds of the tournament.
+func rounds(spec TournamentSpec) int {
+	if spec.Pairing == RoundRobin {
+		return len(spec.Entrants) + len(spec.Entrants)%2 - 1
+	}
+	if spec.Rounds > 0 {
+		return spec.Rounds
+	}
+	return int(math.Ceil(math.Log2(float64(len(spec.Entrants)))))
+}
+
+// roundRobin pairs the entrants with the circle method, the first entrant stays and the others rotate.
+func roundRobin(n int) [][][2]int {
+	players := make([]int, 0, n+1)
+	for i := 0; i < n; i++ {
+		players = append(players, i)
+	}
+	if n%2 == 1 {
+		players = append(players, -1)
+	}
+	var rounds [][][2]int
+	for round := 0; round < len(players)-1; round++ {
+		var pairs [][2]int
+		for i := 0; i < len(players)/2; i++ {
+			a, b := players[i], players[len(players)-1-i]
+			if a < 0 {
+				a, b = b, a
+			}
+			pairs = append(pairs, [2]int{a, b})
+		}
+		rounds = append(rounds, pairs)
+		// rotate all but the first player
+		last := players[len(players)-1]
+		copy(players[2:], players[1:len(players)-1])
+		players[1] = last
+	}
+	return rounds
+}
+
+// swissRound pairs every entrant with the next best entrant it has not played yet.
+// With an odd number of entrants the lowest ranked entrant without a bye so far gets one.
+func swissRound(t *table) [][2]int {
+	order := t.order()
+	var pairs [][2]int
+	if len(order)%2 == 1 {
+		for i := len(order) - 1; i >= 0; i-- {
+			if t.result.Standings[order[i]].Byes == 0 || i == 0 {
+				pairs = append(pairs, [2]int{order[i], -1})
+				order = append(order[:i:i], order[i+1:]...)
+				break
+			}
+		}
+	}
+	paired := make(map[int]bool)
+	for i, a := range order {
+		if paired[a] {
+			continue
+		}
+		opponent := -1
+		for _, b := range order[i+1:] {
+			if paired[b] {
+				continue
+			}
+			if opponent < 0 {
+				// fall back to a rematch if there is no one else left
+				opponent = b
+			}
+			if !t.played[[2]int{a, b}] {
+				opponent = b
+				break
+			}
+		}
+		paired[a], paired[opponent] = true, true
+		pairs = append(pairs, [2]int{a, opponent})
+	}
+	return pairs
+}
+
+// endregion
+
+// region Output
+
+func (r *TournamentResult) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r)
+}
+
+// WriteStandings writes the standings as a table for humans.
+func (r *TournamentResult) WriteStandings(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "Rank\tName\tPlayed\tWins\tDraws\tLosses\tByes\tPoints\tRating\tSugar\t")
+	for _, s := range r.Standings {
+		fmt.Fprintf(tw, "%d\t%s\t%d\t%d\t%d\t%d\t%d\t%g\t%.0f\t%d\t\n",
+			s.Rank, s.Name, s.Played, s.Wins, s.Draws, s.Losses, s.Byes, s.Points, s.Rating, s.SugarDelivered)
+	}
+	return tw.Flush()
+}
+
+// WriteMatchesCSV writes the result of every game, one game per row.
+func (r *TournamentResult) WriteMatchesCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	_ = cw.Write([]string{"round", "scenario", "seed", "a", "b", "score_a", "score_b", "result", "error_a", "error_b"})
+	for _, m := range r.Matches {
+		_ = cw.Write([]string{strconv.Itoa(m.Round), m.Scenario, fmt.Sprint(m.Seed), m.A, m.B,
+			strconv.Itoa(m.ScoreA), strconv.Itoa(m.ScoreB), formatFloat(m.Result), m.ErrorA, m.ErrorB})
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// endregion
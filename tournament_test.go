@@ -0,0 +1,185 @@
+/*
+Copyright (c) 2024 Sebastian Kroczek <me@xbug.de>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package core
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestRoundRobin(t *testing.T) {
+	for n := 2; n <= 9; n++ {
+		rounds := roundRobin(n)
+		if want := robinRounds(n); len(rounds) != want {
+			t.Errorf("%d entrants: %d rounds, want %d", n, len(rounds), want)
+		}
+		met := make(map[[2]int]int)
+		byes := make(map[int]int)
+		for r, pairs := range rounds {
+			seen := make(map[int]bool)
+			for _, pair := range pairs {
+				for _, i := range pair {
+					if i >= 0 && seen[i] {
+						t.Errorf("%d entrants: entrant %d plays twice in round %d", n, i, r)
+					}
+					seen[i] = true
+				}
+				if pair[0] < 0 {
+					t.Errorf("%d entrants: bye %v does not name the entrant first", n, pair)
+				}
+				if pair[1] < 0 {
+					byes[pair[0]]++
+					continue
+				}
+				met[[2]int{min(pair[0], pair[1]), max(pair[0], pair[1])}]++
+			}
+			if len(seen) != n+n%2 {
+				t.Errorf("%d entrants: round %d pairs %v", n, r, pairs)
+			}
+		}
+		for a := 0; a < n; a++ {
+			for b := a + 1; b < n; b++ {
+				if met[[2]int{a, b}] != 1 {
+					t.Errorf("%d entrants: %d and %d meet %d times", n, a, b, met[[2]int{a, b}])
+				}
+			}
+			if want := n % 2; byes[a] != want {
+				t.Errorf("%d entrants: entrant %d has %d byes, want %d", n, a, byes[a], want)
+			}
+		}
+	}
+}
+
+// robinRounds is the number of rounds of a round robin of n entrants.
+func robinRounds(n int) int {
+	return rounds(TournamentSpec{Pairing: RoundRobin, Entrants: make([]Entrant, n)})
+}
+
+func TestSwissRound(t *testing.T) {
+	tests := []struct {
+		name   string
+		points []float64
+		byes   []int
+		played [][2]int
+		want   [][2]int
+	}{
+		{
+			name:   "best plays next best",
+			points: []float64{3, 2, 1, 0},
+			want:   [][2]int{{0, 1}, {2, 3}},
+		},
+		{
+			name:   "no rematch",
+			points: []float64{3, 2, 1, 0},
+			played: [][2]int{{0, 1}},
+			want:   [][2]int{{0, 2}, {1, 3}},
+		},
+		{
+			name:   "rematch if no one else is left",
+			points: []float64{3, 2, 1, 0},
+			played: [][2]int{{0, 1}, {0, 2}, {0, 3}},
+			want:   [][2]int{{0, 1}, {2, 3}},
+		},
+		{
+			name:   "lowest gets the bye",
+			points: []float64{4, 3, 2, 1, 0},
+			want:   [][2]int{{4, -1}, {0, 1}, {2, 3}},
+		},
+		{
+			name:   "a single bye per entrant",
+			points: []float64{4, 3, 2, 1, 0},
+			byes:   []int{0, 0, 0, 0, 1},
+			want:   [][2]int{{3, -1}, {0, 1}, {2, 4}},
+		},
+		{
+			name:   "the best gets the bye if everyone had one",
+			points: []float64{2, 1, 0},
+			byes:   []int{1, 1, 1},
+			want:   [][2]int{{0, -1}, {1, 2}},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			table := newTable(make([]Entrant, len(test.points)))
+			for i, points := range test.points {
+				table.result.Standings[i].Points = points
+			}
+			for i, byes := range test.byes {
+				table.result.Standings[i].Byes = byes
+			}
+			for _, pair := range test.played {
+				table.played[pair] = true
+				table.played[[2]int{pair[1], pair[0]}] = true
+			}
+			if got := swissRound(table); !reflect.DeepEqual(got, test.want) {
+				t.Errorf("pairs %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestRecord(t *testing.T) {
+	tests := []struct {
+		name             string
+		ratingA, ratingB float64
+		result           float64
+		delta            float64
+	}{
+		{"win of equals", 1500, 1500, 1, 16},
+		{"loss of equals", 1500, 1500, 0, -16},
+		{"draw of equals", 1500, 1500, 0.5, 0},
+		{"expected win", 1600, 1400, 1, 32 * (1 - 1/(1+math.Pow(10, -0.5)))},
+		{"upset", 1400, 1600, 1, 32 * (1 - 1/(1+math.Pow(10, 0.5)))},
+		{"draw against the better", 1400, 1600, 0.5, 32 * (0.5 - 1/(1+math.Pow(10, 0.5)))},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			table := newTable(make([]Entrant, 2))
+			a, b := &table.result.Standings[0], &table.result.Standings[1]
+			a.Rating, b.Rating = test.ratingA, test.ratingB
+			table.record(0, 1, test.result, defaultKFactor)
+			if math.Abs(a.Rating-test.ratingA-test.delta) > 1e-9 || math.Abs(b.Rating-test.ratingB+test.delta) > 1e-9 {
+				t.Errorf("ratings %g and %g, want %g and %g", a.Rating, b.Rating,
+					test.ratingA+test.delta, test.ratingB-test.delta)
+			}
+			if a.Points != test.result || b.Points != 1-test.result {
+				t.Errorf("points %g and %g for result %g", a.Points, b.Points, test.result)
+			}
+			if a.Played != 1 || b.Played != 1 || a.Wins+a.Draws+a.Losses != 1 || b.Wins+b.Draws+b.Losses != 1 {
+				t.Errorf("counts %+v and %+v", *a, *b)
+			}
+			if !table.played[[2]int{0, 1}] || !table.played[[2]int{1, 0}] {
+				t.Error("the pairing is not remembered")
+			}
+		})
+	}
+}
+
+func TestBye(t *testing.T) {
+	table := newTable(make([]Entrant, 3))
+	table.bye(2, 6)
+	if s := table.result.Standings[2]; s.Byes != 6 || s.Points != 6 || s.Played != 0 || s.Rating != initialRating {
+		t.Errorf("standing after a bye of 6 games %+v", s)
+	}
+}
@@ -0,0 +1,109 @@
+/*
+Copyright (c) 2024 Sebastian Kroczek <me@xbug.de>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package wasm
+
+import (
+	"context"
+	"fmt"
+	"github.com/gotameme/core/ant"
+	"github.com/tetratelabs/wazero/api"
+	"sync"
+)
+
+type antKey struct{}
+
+func antFrom(ctx context.Context) *wasmAnt {
+	return ctx.Value(antKey{}).(*wasmAnt)
+}
+
+// wasmAnt forwards the callbacks of an ant to its instance of the module.
+type wasmAnt struct {
+	module   *Module
+	os       ant.AntOs
+	instance api.Module
+
+	// callbacks of an ant may run concurrently, but an instance can only run one function at a time
+	mu     sync.Mutex
+	sugars map[int]ant.Sugar
+}
+
+func (a *wasmAnt) Waits() {
+	a.call("waits")
+}
+
+func (a *wasmAnt) SeeSugar(sugar ant.Sugar) {
+	a.callWithSugar("see_sugar", sugar)
+}
+
+func (a *wasmAnt) ReachedSugar(sugar ant.Sugar) {
+	a.callWithSugar("reached_sugar", sugar)
+}
+
+func (a *wasmAnt) SeeMark(mark ant.Mark) {
+	a.call("see_mark", api.EncodeI32(int32(mark.GetInformation())))
+}
+
+func (a *wasmAnt) Hear(from ant.FriendInfo, msg int) {
+	a.call("hear",
+		api.EncodeI32(int32(from.Id)),
+		api.EncodeI32(int32(from.Direction)),
+		api.EncodeI32(int32(from.Distance)),
+		api.EncodeI32(int32(msg)))
+}
+
+func (a *wasmAnt) Tick() {
+	a.call("tick")
+}
+
+func (a *wasmAnt) callWithSugar(name string, sugar ant.Sugar) {
+	a.mu.Lock()
+	a.sugars[sugar.GetId()] = sugar
+	a.mu.Unlock()
+	a.call(name, api.EncodeI32(int32(sugar.GetId())))
+}
+
+// context returns the context of a callback, limited by the fuel and the timeout of the module.
+func (a *wasmAnt) context() (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithTimeout(context.WithValue(context.Background(), antKey{}, a), a.module.timeout)
+	return withFuel(ctx, a.module.fuel, cancel), cancel
+}
+
+func (a *wasmAnt) call(name string, params ...uint64) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	fn := a.instance.ExportedFunction(name)
+	if fn == nil {
+		return
+	}
+	ctx, cancel := a.context()
+	defer cancel()
+	if _, err := fn.Call(ctx, params...); err != nil {
+		panic(fmt.Errorf("wasm ant %s: %w", name, err))
+	}
+	// forget the sugar that is gone
+	for id, sugar := range a.sugars {
+		if sugar.GetCurrentSugar() <= 0 {
+			delete(a.sugars, id)
+		}
+	}
+}
@@ -0,0 +1,62 @@
+/*
+Copyright (c) 2024 Sebastian Kroczek <me@xbug.de>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package wasm
+
+import (
+	"context"
+	"github.com/tetratelabs/wazero/api"
+	"github.com/tetratelabs/wazero/experimental"
+	"sync/atomic"
+)
+
+type fuelKey struct{}
+
+// fuel is the number of function calls left for the current callback, the callback is canceled if it runs out.
+type fuel struct {
+	left   atomic.Int64
+	cancel context.CancelFunc
+}
+
+func withFuel(ctx context.Context, amount uint64, cancel context.CancelFunc) context.Context {
+	f := &fuel{cancel: cancel}
+	f.left.Store(int64(amount))
+	return context.WithValue(ctx, fuelKey{}, f)
+}
+
+// fuelListener consumes one unit of fuel per function call, it is attached to the functions of the module when the
+// module is compiled.
+type fuelListener struct{}
+
+func (l fuelListener) NewFunctionListener(api.FunctionDefinition) experimental.FunctionListener {
+	return l
+}
+
+func (fuelListener) Before(ctx context.Context, _ api.Module, _ api.FunctionDefinition, _ []uint64, _ experimental.StackIterator) {
+	if f, ok := ctx.Value(fuelKey{}).(*fuel); ok && f.left.Add(-1) < 0 {
+		f.cancel()
+	}
+}
+
+func (fuelListener) After(context.Context, api.Module, api.FunctionDefinition, []uint64) {}
+
+func (fuelListener) Abort(context.Context, api.Module, api.FunctionDefinition, error) {}
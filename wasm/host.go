@@ -0,0 +1,129 @@
+/*
+Copyright (c) 2024 Sebastian Kroczek <me@xbug.de>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package wasm
+
+import (
+	"context"
+	"github.com/tetratelabs/wazero"
+	"github.com/tetratelabs/wazero/api"
+)
+
+const hostModuleName = "antos"
+
+// instantiateHostModule provides the functions of ant.AntOs to the modules, the calling ant is taken from the context.
+func instantiateHostModule(ctx context.Context, r wazero.Runtime) error {
+	_, err := r.NewHostModuleBuilder(hostModuleName).
+		NewFunctionBuilder().WithFunc(func(ctx context.Context) int32 {
+		return int32(antFrom(ctx).os.GetId())
+	}).Export("get_id").
+		NewFunctionBuilder().WithFunc(func(ctx context.Context, m api.Module, ptr, length uint32) int32 {
+		role := antFrom(ctx).os.GetRole()
+		if n := uint32(len(role)); n < length {
+			length = n
+		}
+		m.Memory().Write(ptr, []byte(role)[:length])
+		return int32(len(role))
+	}).Export("get_role").
+		NewFunctionBuilder().WithFunc(func(ctx context.Context) int32 {
+		return int32(antFrom(ctx).os.GetCurrentLoad())
+	}).Export("get_current_load").
+		NewFunctionBuilder().WithFunc(func(ctx context.Context) int32 {
+		return int32(antFrom(ctx).os.GetOrder())
+	}).Export("get_order").
+		NewFunctionBuilder().WithFunc(func(ctx context.Context, sugarID int32) int32 {
+		a := antFrom(ctx)
+		if sugar, ok := a.sugars[int(sugarID)]; ok {
+			return int32(a.os.GetDirectionToSugar(sugar))
+		}
+		return 0
+	}).Export("get_direction_to_sugar").
+		NewFunctionBuilder().WithFunc(func(ctx context.Context, sugarID int32) int32 {
+		if sugar, ok := antFrom(ctx).sugars[int(sugarID)]; ok {
+			return int32(sugar.GetCurrentSugar())
+		}
+		return 0
+	}).Export("get_sugar_amount").
+		NewFunctionBuilder().WithFunc(func(ctx context.Context) {
+		antFrom(ctx).os.GoForwards()
+	}).Export("go_forwards").
+		NewFunctionBuilder().WithFunc(func(ctx context.Context, steps int32) {
+		antFrom(ctx).os.GoForward(int(steps))
+	}).Export("go_forward").
+		NewFunctionBuilder().WithFunc(func(ctx context.Context, direction int32) {
+		antFrom(ctx).os.Turn(int(direction))
+	}).Export("turn").
+		NewFunctionBuilder().WithFunc(func(ctx context.Context, sugarID int32) {
+		a := antFrom(ctx)
+		if sugar, ok := a.sugars[int(sugarID)]; ok {
+			a.os.GoToSugar(sugar)
+		}
+	}).Export("go_to_sugar").
+		NewFunctionBuilder().WithFunc(func(ctx context.Context, sugarID int32) int32 {
+		a := antFrom(ctx)
+		if sugar, ok := a.sugars[int(sugarID)]; ok && a.os.TakeSugar(sugar) == nil {
+			return 0
+		}
+		return 1
+	}).Export("take_sugar").
+		NewFunctionBuilder().WithFunc(func(ctx context.Context) {
+		antFrom(ctx).os.GotToAntHill()
+	}).Export("go_to_ant_hill").
+		NewFunctionBuilder().WithFunc(func(ctx context.Context, radius, information int32) {
+		antFrom(ctx).os.SetMark(int(radius), int(information))
+	}).Export("set_mark").
+		NewFunctionBuilder().WithFunc(func(ctx context.Context, msg int32) int32 {
+		if antFrom(ctx).os.Say(int(msg)) != nil {
+			return 1
+		}
+		return 0
+	}).Export("say").
+		NewFunctionBuilder().WithFunc(func(ctx context.Context, m api.Module, ptr, length uint32) {
+		if msg, ok := m.Memory().Read(ptr, length); ok {
+			antFrom(ctx).os.Log(string(msg))
+		}
+	}).Export("log").
+		NewFunctionBuilder().WithFunc(func(ctx context.Context, m api.Module, keyPtr, keyLength, valuePtr uint32) int32 {
+		key, ok := m.Memory().Read(keyPtr, keyLength)
+		if !ok {
+			return 0
+		}
+		value, ok := antFrom(ctx).os.Colony().Get(string(key))
+		if !ok {
+			return 0
+		}
+		i, ok := value.(int64)
+		if !ok || !m.Memory().WriteUint64Le(valuePtr, uint64(i)) {
+			return 0
+		}
+		return 1
+	}).Export("colony_get").
+		NewFunctionBuilder().WithFunc(func(ctx context.Context, m api.Module, keyPtr, keyLength uint32, value int64) int32 {
+		key, ok := m.Memory().Read(keyPtr, keyLength)
+		if !ok || antFrom(ctx).os.Colony().Set(string(key), value) != nil {
+			return 1
+		}
+		return 0
+	}).Export("colony_set").
+		Instantiate(ctx)
+	return err
+}
@@ -0,0 +1,225 @@
+/*
+Copyright (c) 2024 Sebastian Kroczek <me@xbug.de>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+// Package wasm runs ants compiled to WebAssembly in a sandbox, using the pure Go runtime wazero.
+//
+// Every ant gets its own instance of the module, so global variables of the module are the memory of the ant.
+// A module may export the following functions, all of them are optional and mirror the callbacks of ant.Ant:
+//
+//	waits()
+//	see_sugar(sugar i32)
+//	reached_sugar(sugar i32)
+//	see_mark(information i32)
+//	hear(from_id i32, from_direction i32, from_distance i32, msg i32)
+//	tick()
+//
+// The module can import the following functions of the module "antos", they mirror ant.AntOs. Sugar is referenced
+// by its id, only sugar the ant has seen or reached can be referenced. Strings are passed as pointer and length into
+// the memory of the module.
+//
+//	get_id() i32
+//	get_role(ptr i32, len i32) i32 // copies at most len bytes of the role to ptr and returns the length of the role
+//	get_current_load() i32
+//	get_order() i32
+//	get_direction_to_sugar(sugar i32) i32
+//	get_sugar_amount(sugar i32) i32
+//	go_forwards()
+//	go_forward(steps i32)
+//	turn(direction i32)
+//	go_to_sugar(sugar i32)
+//	take_sugar(sugar i32) i32 // returns 0 on success
+//	go_to_ant_hill()
+//	set_mark(radius i32, information i32)
+//	say(msg i32) i32 // returns 0 on success
+//	log(ptr i32, len i32) // logs the message of len bytes at ptr
+//	colony_get(key_ptr i32, key_len i32, value_ptr i32) i32 // writes the i64 value to value_ptr, returns 1 if found
+//	colony_set(key_ptr i32, key_len i32, value i64) i32 // returns 0 on success
+//
+// Modules built for WASI are supported, they neither get access to the file system nor to the network.
+// Each module has a memory limit and every callback has a fuel limit and a timeout. Fuel is consumed by every
+// function call inside the module, loops without calls are only bounded by the timeout.
+package wasm
+
+import (
+	"context"
+	"fmt"
+	"github.com/gotameme/core/ant"
+	"github.com/tetratelabs/wazero"
+	"github.com/tetratelabs/wazero/api"
+	"github.com/tetratelabs/wazero/experimental"
+	"github.com/tetratelabs/wazero/imports/wasi_snapshot_preview1"
+	"os"
+	"sync"
+	"time"
+)
+
+const (
+	defaultMemoryLimitPages = 256 // 16 MiB
+	defaultFuel             = 100_000
+	defaultTimeout          = 100 * time.Millisecond
+)
+
+type Option func(*Module)
+
+// WithMemoryLimit limits the memory of every instance of the module to the given number of 64 KiB pages.
+func WithMemoryLimit(pages uint32) Option {
+	return func(m *Module) {
+		m.memoryLimitPages = pages
+	}
+}
+
+// WithFuel limits the number of function calls inside the module per callback.
+func WithFuel(fuel uint64) Option {
+	return func(m *Module) {
+		m.fuel = fuel
+	}
+}
+
+// WithTimeout limits the time a callback may take.
+func WithTimeout(timeout time.Duration) Option {
+	return func(m *Module) {
+		m.timeout = timeout
+	}
+}
+
+// Module is a compiled WebAssembly ant.
+type Module struct {
+	memoryLimitPages uint32
+	fuel             uint64
+	timeout          time.Duration
+
+	runtime  wazero.Runtime
+	compiled wazero.CompiledModule
+}
+
+// Load compiles the WebAssembly module at the given path.
+func Load(path string, options ...Option) (*Module, error) {
+	binary, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return New(binary, options...)
+}
+
+// New compiles the given WebAssembly module.
+func New(binary []byte, options ...Option) (*Module, error) {
+	m := &Module{
+		memoryLimitPages: defaultMemoryLimitPages,
+		fuel:             defaultFuel,
+		timeout:          defaultTimeout,
+	}
+	for _, option := range options {
+		option(m)
+	}
+
+	ctx := context.Background()
+	m.runtime = wazero.NewRuntimeWithConfig(ctx, wazero.NewRuntimeConfig().
+		WithMemoryLimitPages(m.memoryLimitPages).
+		WithCloseOnContextDone(true))
+	if _, err := wasi_snapshot_preview1.Instantiate(ctx, m.runtime); err != nil {
+		_ = m.Close()
+		return nil, err
+	}
+	if err := instantiateHostModule(ctx, m.runtime); err != nil {
+		_ = m.Close()
+		return nil, err
+	}
+	compiled, err := m.runtime.CompileModule(experimental.WithFunctionListenerFactory(ctx, fuelListener{}), binary)
+	if err != nil {
+		_ = m.Close()
+		return nil, fmt.Errorf("compile wasm module: %w", err)
+	}
+	m.compiled = compiled
+	return m, nil
+}
+
+// AntConstructor returns a constructor that instantiates the module for every ant.
+// The callbacks of these ants panic if the module traps, runs out of fuel or time, so the fault policy of the
+// simulation applies.
+func (m *Module) AntConstructor() ant.AntConstructor {
+	return func(antOs ant.AntOs) interface{} {
+		return m.instantiate(antOs)
+	}
+}
+
+func (m *Module) instantiate(antOs ant.AntOs) *wasmAnt {
+	a := &wasmAnt{module: m, os: antOs, sugars: make(map[int]ant.Sugar)}
+	ctx, cancel := a.context()
+	defer cancel()
+	instance, err := m.runtime.InstantiateModule(ctx, m.compiled, wazero.NewModuleConfig().
+		WithName("").
+		WithStartFunctions("_initialize", "_start"))
+	if err != nil {
+		panic(fmt.Errorf("instantiate wasm module: %w", err))
+	}
+	a.instance = instance
+	return a
+}
+
+// Close releases the runtime and all instances of the module.
+func (m *Module) Close() error {
+	return m.runtime.Close(context.Background())
+}
+
+// Session keeps the instances of the ants of one run, so they can be released when the run ends while the module
+// stays compiled for the next runs.
+type Session struct {
+	module    *Module
+	mu        sync.Mutex
+	instances []api.Module
+	closed    bool
+}
+
+// NewSession starts a session, every session should be closed when its run ends.
+func (m *Module) NewSession() *Session {
+	return &Session{module: m}
+}
+
+// AntConstructor returns a constructor that instantiates the module for every ant, like Module.AntConstructor.
+func (s *Session) AntConstructor() ant.AntConstructor {
+	return func(antOs ant.AntOs) interface{} {
+		a := s.module.instantiate(antOs)
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		if s.closed {
+			_ = a.instance.Close(context.Background())
+			panic("wasm session is closed")
+		}
+		s.instances = append(s.instances, a.instance)
+		return a
+	}
+}
+
+// Close releases the instances of the session, the module stays usable.
+func (s *Session) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.closed = true
+	var first error
+	for _, instance := range s.instances {
+		if err := instance.Close(context.Background()); first == nil {
+			first = err
+		}
+	}
+	s.instances = nil
+	return first
+}